@@ -0,0 +1,8 @@
+data "awscloud9_environment_memberships" "writers" {
+  environment_id = var.env_id
+  permissions    = "read-write"
+}
+
+output "writer_arns" {
+  value = data.awscloud9_environment_memberships.writers.memberships[*].user_arn
+}
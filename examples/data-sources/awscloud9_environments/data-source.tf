@@ -0,0 +1,16 @@
+data "awscloud9_environments" "infra" {
+  environment_type = "ssh"
+  name_regex       = "^infra-"
+
+  tags = {
+    team = "infra"
+  }
+}
+
+resource "awscloud9_environment_membership" "oncall" {
+  for_each = toset(data.awscloud9_environments.infra.ids)
+
+  environment_id = each.value
+  permissions    = "read-write"
+  user_arn       = var.oncall_arn
+}
@@ -1,5 +1,16 @@
 data "awscloud9_ssh_environment" "env" {
-  environment_id = var.env_id
+  id = var.env_id
+}
+
+data "awscloud9_ssh_environment" "by_name" {
+  name = "infra"
+}
+
+data "awscloud9_ssh_environment" "by_tags" {
+  filter_tags = {
+    team  = "infra"
+    stage = "prod"
+  }
 }
 
 output "env_arn" {
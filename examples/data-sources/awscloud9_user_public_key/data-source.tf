@@ -0,0 +1,7 @@
+data "awscloud9_user_public_key" "key" {
+  from = ["10.0.0.0/8"]
+}
+
+output "authorized_key" {
+  value = data.awscloud9_user_public_key.key.authorized_key
+}
@@ -1,4 +1,3 @@
-
 # Configuration-based authentication
 provider "awscloud9" {
   aws_access_key_id     = "..."
@@ -10,3 +9,56 @@ provider "awscloud9" {
 provider "awscloud9" {
   region = "us-east-1"
 }
+
+# Shared config profile, such as an SSO profile
+provider "awscloud9" {
+  profile = "my-sso-profile"
+}
+
+# Assumed role on top of the default credential chain
+provider "awscloud9" {
+  region = "us-east-1"
+
+  assume_role {
+    role_arn     = "arn:aws:iam::123456789012:role/cloud9-admin"
+    session_name = "terraform"
+    external_id  = "..."
+    duration     = "1h"
+  }
+}
+
+# Web identity token, such as an OIDC token issued by a CI system
+provider "awscloud9" {
+  region = "us-east-1"
+
+  assume_role_with_web_identity {
+    role_arn                = "arn:aws:iam::123456789012:role/ci"
+    web_identity_token_file = "/var/run/secrets/token"
+  }
+}
+
+# Custom endpoints, such as FIPS endpoints or a local emulator
+provider "awscloud9" {
+  region = "us-east-1"
+
+  endpoints {
+    cloud9 = "https://cloud9-fips.us-east-1.amazonaws.com"
+    sts    = "https://sts-fips.us-east-1.amazonaws.com"
+  }
+}
+
+# Tags added to every environment, and tags managed by other tools
+provider "awscloud9" {
+  region = "us-east-1"
+
+  default_tags {
+    tags = {
+      managed-by = "terraform"
+    }
+  }
+
+  ignore_tags {
+    keys         = ["last-scanned"]
+    key_prefixes = ["kubernetes.io/"]
+  }
+}
@@ -0,0 +1,6 @@
+# environment can be imported with its id, instance_type, image_id, subnet_id
+# and automatic_stop_time_minutes are not returned by the API and are set from
+# the configuration on the next apply, without replacing the environment.
+# They are not checked against the environment and must match the settings it
+# was created with, any later change replaces the environment.
+terraform import awscloud9_ec2_environment.env 2a8701dd3fc75a2da815ee2047f784d8
@@ -0,0 +1,22 @@
+# Basic EC2 environment
+resource "awscloud9_ec2_environment" "env" {
+  name          = "my_environment"
+  instance_type = "t3.small"
+  image_id      = "amazonlinux-2023-x86_64"
+
+  tags = {
+    "managed-by" = "terraform"
+  }
+}
+
+# EC2 environment reachable through SSM in a private subnet
+resource "awscloud9_ec2_environment" "private_env" {
+  name        = "my_private_environment"
+  description = "An EC2 environment without inbound access"
+
+  instance_type               = "t3.small"
+  image_id                    = "amazonlinux-2023-x86_64"
+  subnet_id                   = "subnet-0123456789abcdef0"
+  connection_type             = "CONNECT_SSM"
+  automatic_stop_time_minutes = 30
+}
@@ -1,9 +1,9 @@
 data "awscloud9_ssh_environment" "env" {
-  environment_id = "..."
+  id = "..."
 }
 
 resource "awscloud9_environment_membership" "membership" {
-  environment_id = awscloud9_ssh_environment.env.environment_id
+  environment_id = data.awscloud9_ssh_environment.env.id
   permissions    = "read-write"
   user_arn       = "arn:aws:..."
 }
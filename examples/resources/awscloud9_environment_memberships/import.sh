@@ -0,0 +1,2 @@
+# The members of an environment can be imported with the environment id
+terraform import awscloud9_environment_memberships.members 2a8701dd3fc75a2da815ee2047f784d8
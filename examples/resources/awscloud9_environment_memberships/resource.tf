@@ -0,0 +1,14 @@
+resource "awscloud9_environment_memberships" "members" {
+  environment_id = awscloud9_ssh_environment.env.id
+
+  members = [
+    {
+      user_arn    = "arn:aws:iam::123456789012:user/alice"
+      permissions = "read-write"
+    },
+    {
+      user_arn    = "arn:aws:iam::123456789012:user/bob"
+      permissions = "read-only"
+    },
+  ]
+}
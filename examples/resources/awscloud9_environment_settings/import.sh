@@ -0,0 +1,2 @@
+# Environment settings can be imported with the environment id
+terraform import awscloud9_environment_settings.settings 2a8701dd3fc75a2da815ee2047f784d8
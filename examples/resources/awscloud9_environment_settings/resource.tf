@@ -0,0 +1,16 @@
+data "awscloud9_ssh_environment" "env" {
+  id = "..."
+}
+
+resource "awscloud9_environment_settings" "settings" {
+  environment_id = data.awscloud9_ssh_environment.env.id
+  settings = jsonencode({
+    run = {
+      configs = {
+        test = {
+          command = "make test"
+        }
+      }
+    }
+  })
+}
@@ -24,3 +24,25 @@ resource "awscloud9_ssh_environment" "env_with_bastion" {
 
   bastion_url = "my_user@my.proxy.com:22"
 }
+
+# SSH Environment with a bastion block
+resource "awscloud9_ssh_environment" "env_with_bastion_block" {
+  name       = "my_other_protected_environment"
+  login_name = "my_user"
+  hostname   = "my-other-host.ec2.amazonaws.com"
+
+  bastion {
+    user = "my_user"
+    host = "my.proxy.com"
+    port = 2222
+  }
+}
+
+# SSH Environment whose host is checked before it is created
+resource "awscloud9_ssh_environment" "checked_env" {
+  name       = "my_checked_environment"
+  login_name = "my_user"
+  hostname   = "my-host.ec2.amazonaws.com"
+
+  validate_connectivity = true
+}
@@ -0,0 +1,2 @@
+# The settings of the caller are imported with the `user` id
+terraform import awscloud9_user_settings.settings user
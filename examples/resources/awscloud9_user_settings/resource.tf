@@ -0,0 +1,8 @@
+resource "awscloud9_user_settings" "settings" {
+  settings = jsonencode({
+    ace = {
+      tabSize = 2
+      theme   = "ace/theme/monokai"
+    }
+  })
+}
@@ -4,14 +4,15 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/credentials"
+	awsclient "github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	"github.com/aws/aws-sdk-go/service/cloud9"
@@ -19,13 +20,15 @@ import (
 
 const (
 	DEFAULT_METHOD   = "POST"
-	SERVICE          = "cloud9"
-	URL_PATTERN      = "https://%s.%s.amazonaws.com/"
 	OPERATION_PREFIX = "AWSCloud9WorkspaceManagementService"
 	AWS_JSON         = "application/x-amz-json-1.1"
 	MAX_RESULTS      = 25
 )
 
+// AWSCloud9Client sends the operations missing from the SDK through
+// executeCloud9 and the others through the Cloud9 SDK client. Both paths
+// share the credentials, region, endpoint, http client and retry options of
+// the client.
 type AWSCloud9Client struct {
 	region  string
 	client  *http.Client
@@ -33,24 +36,57 @@ type AWSCloud9Client struct {
 	signer  *v4.Signer
 	url     string
 	Cloud9  *cloud9.Cloud9
-	ctx     context.Context
 	session *session.Session
+	options ClientOptions
+	limiter *rateLimiter
+	// cache is nil unless ClientOptions.CacheReads is set.
+	cache *readCache
 }
 
-func New(ctx context.Context, credentials *credentials.Credentials, region string) *AWSCloud9Client {
-	config := aws.NewConfig().WithRegion(region)
-	session := session.Must(session.NewSession())
+func New(sess *session.Session, options ClientOptions) *AWSCloud9Client {
+	options.setDefaults()
 
-	client := cloud9.New(session, config)
-	return &AWSCloud9Client{
-		region:  region,
-		client:  &http.Client{},
-		service: SERVICE,
-		signer:  v4.NewSigner(credentials),
-		url:     fmt.Sprintf(URL_PATTERN, SERVICE, region),
-		ctx:     ctx,
+	httpClient := &http.Client{}
+	if sess.Config.HTTPClient != nil {
+		*httpClient = *sess.Config.HTTPClient
+	}
+	httpClient.Timeout = options.RequestTimeout
+
+	config := aws.NewConfig().WithHTTPClient(httpClient)
+	if len(options.Endpoint) > 0 {
+		config = config.WithEndpoint(options.Endpoint)
+	}
+	config = request.WithRetryer(config, sdkRetryer{awsclient.DefaultRetryer{
+		NumMaxRetries:    options.MaxRetries,
+		MinRetryDelay:    options.MinRetryDelay,
+		MaxRetryDelay:    options.MaxRetryDelay,
+		MinThrottleDelay: options.minThrottleDelay(),
+		MaxThrottleDelay: options.MaxRetryDelay,
+	}})
+	client := cloud9.New(sess, config)
+
+	res := &AWSCloud9Client{
+		region:  client.SigningRegion,
+		client:  httpClient,
+		service: client.SigningName,
+		signer:  v4.NewSigner(sess.Config.Credentials),
+		url:     strings.TrimSuffix(client.Endpoint, "/") + "/",
 		Cloud9:  client,
+		session: sess,
+		options: options,
+		limiter: newRateLimiter(options.RequestsPerSecond),
 	}
+
+	if options.CacheReads {
+		res.cache = newReadCache()
+		client.Handlers.Complete.PushBackNamed(res.cache.sdkInvalidateHandler())
+	}
+
+	logAttempt, logCall := res.sdkLogHandlers()
+	client.Handlers.CompleteAttempt.PushBackNamed(logAttempt)
+	client.Handlers.Complete.PushBackNamed(logCall)
+
+	return res
 }
 
 func (client *AWSCloud9Client) signRequest(request *http.Request, body io.ReadSeeker) error {
@@ -62,14 +98,14 @@ func (client *AWSCloud9Client) signRequest(request *http.Request, body io.ReadSe
 	return nil
 }
 
-func (client *AWSCloud9Client) executeCloud9(operation string, body interface{}) (*http.Response, error) {
+func (client *AWSCloud9Client) executeCloud9(ctx context.Context, operation string, body interface{}) (*http.Response, error) {
 
 	bodyString, err := json.Marshal(body)
 	if err != nil {
 		return nil, err
 	}
 
-	request, err := http.NewRequest(DEFAULT_METHOD, client.url, bytes.NewReader(bodyString))
+	request, err := http.NewRequestWithContext(ctx, DEFAULT_METHOD, client.url, bytes.NewReader(bodyString))
 	if err != nil {
 		return nil, err
 	}
@@ -85,113 +121,195 @@ func (client *AWSCloud9Client) executeCloud9(operation string, body interface{})
 	return client.client.Do(request)
 }
 
-func (client *AWSCloud9Client) GetUserPublicKey() (*GetUserPublicKeyResult, error) {
-	var body struct{}
-	res, err := client.executeCloud9("GetUserPublicKey", body)
-	if err != nil {
-		return nil, err
+// callCloud9 sends the operation and decodes the response into output when
+// not nil, retrying throttled and failed attempts. Any non-2xx response is
+// returned as a *Cloud9Error.
+func (client *AWSCloud9Client) callCloud9(ctx context.Context, operation string, input interface{}, output interface{}) error {
+	defer client.cache.invalidateAfter(operation, input)
+
+	call := &callLog{
+		Target:        fmt.Sprintf("%s.%s", OPERATION_PREFIX, operation),
+		EnvironmentId: environmentIdOf(input),
+		Start:         time.Now(),
+	}
+	defer func() {
+		client.logCall(ctx, call)
+	}()
+
+	for attempt := 0; ; attempt++ {
+		call.Retries = attempt
+		res, bodyBytes, err := client.attemptCloud9(ctx, operation, attempt, input)
+		if res != nil {
+			call.StatusCode = res.StatusCode
+			call.RequestID = res.Header.Get(REQUEST_ID_HEADER)
+		}
+		if err != nil && attempt < client.options.MaxRetries && isRetryable(operation, err) {
+			if err = sleep(ctx, client.options.retryDelay(attempt, err, res)); err != nil {
+				call.Err = err
+				return err
+			}
+			continue
+		} else if err != nil {
+			call.Err = err
+			return err
+		}
+
+		if output == nil {
+			return nil
+		}
+		call.Err = json.Unmarshal(bodyBytes, output)
+		return call.Err
 	}
+}
 
+func (client *AWSCloud9Client) attemptCloud9(ctx context.Context, operation string, attempt int, input interface{}) (*http.Response, []byte, error) {
+	res, err := client.executeCloud9(ctx, operation, input)
+	if err != nil {
+		return nil, nil, err
+	}
 	bodyBytes, err := io.ReadAll(res.Body)
 	defer res.Body.Close()
+
+	var requestBody []byte
+	if client.options.LogBodies {
+		requestBody, _ = json.Marshal(input)
+	}
+	client.logAttempt(ctx, fmt.Sprintf("%s.%s", OPERATION_PREFIX, operation), attempt, res.Request, res, requestBody, bodyBytes)
+
 	if err != nil {
-		return nil, err
+		return res, nil, err
 	}
-	if res.StatusCode == 400 {
-		var error AWSError
-		if err = json.Unmarshal(bodyBytes, &error); err != nil {
-			return nil, err
-		}
-		return nil, errors.New(fmt.Sprintf("An error occured of type %s: %s", error.ExceptionType, error.Message))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res, bodyBytes, newResponseError(res, bodyBytes)
 	}
-	var result GetUserPublicKeyResult
-	if err = json.Unmarshal(bodyBytes, &result); err != nil {
+	return res, bodyBytes, nil
+}
+
+// wrapError converts the request failures of the SDK client to *Cloud9Error.
+func wrapError(err error) error {
+	if cloud9Err, ok := AsCloud9Error(err); ok {
+		return cloud9Err
+	}
+	return err
+}
+
+func (client *AWSCloud9Client) GetUserPublicKey(ctx context.Context) (*GetUserPublicKeyResult, error) {
+	var body struct{}
+	value, err := client.cache.get(ctx, "GetUserPublicKey", body, nil, func() (interface{}, error) {
+		var result GetUserPublicKeyResult
+		err := client.callCloud9(ctx, "GetUserPublicKey", body, &result)
+		return result, err
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	result := value.(GetUserPublicKeyResult)
 	return &result, nil
 }
 
-func (client *AWSCloud9Client) DescribeSSHRemote(environmentId string) (*DescribeSSHRemoteResult, error) {
+func (client *AWSCloud9Client) DescribeSSHRemote(ctx context.Context, environmentId string) (*DescribeSSHRemoteResult, error) {
+	return client.describeSSHRemote(ctx, environmentId, nil)
+}
+
+// describeSSHRemote waits for throttle, when not nil, before calling the
+// API. Cache hits are not throttled.
+func (client *AWSCloud9Client) describeSSHRemote(ctx context.Context, environmentId string, throttle func(ctx context.Context) error) (*DescribeSSHRemoteResult, error) {
 	request := DescribeSSHRemoteRequest{
 		EnvironmentId: environmentId,
 	}
 
-	res, err := client.executeCloud9("DescribeSSHRemote", request)
-	if err != nil {
-		return nil, err
-	}
-	bodyBytes, err := io.ReadAll(res.Body)
-	defer res.Body.Close()
-	if err != nil {
-		return nil, err
-	}
-
-	if res.StatusCode == 400 {
-		var error AWSError
-		if err = json.Unmarshal(bodyBytes, &error); err != nil {
-			return nil, err
+	value, err := client.cache.get(ctx, "DescribeSSHRemote", request, []string{environmentId}, func() (interface{}, error) {
+		if throttle != nil {
+			if err := throttle(ctx); err != nil {
+				return nil, err
+			}
 		}
-		return nil, errors.New(fmt.Sprintf("An error occured of type %s: %s", error.ExceptionType, error.Message))
-	}
-	var result DescribeSSHRemoteResult
-	if err = json.Unmarshal(bodyBytes, &result); err != nil {
+		var result DescribeSSHRemoteResult
+		err := client.callCloud9(ctx, "DescribeSSHRemote", request, &result)
+		return result, err
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	result := value.(DescribeSSHRemoteResult)
 	return &result, nil
 }
 
-func (client *AWSCloud9Client) UpdateSSHRemote(request *UpdateSSHRemoteRequest) error {
+func (client *AWSCloud9Client) UpdateSSHRemote(ctx context.Context, request *UpdateSSHRemoteRequest) error {
+	return client.callCloud9(ctx, "UpdateSSHRemote", request, nil)
+}
 
-	res, err := client.executeCloud9("UpdateSSHRemote", request)
-	if err != nil {
-		return err
-	}
-	bodyBytes, err := io.ReadAll(res.Body)
-	defer res.Body.Close()
+// GetUserSettings returns the IDE settings of the caller, a JSON document.
+func (client *AWSCloud9Client) GetUserSettings(ctx context.Context) (string, error) {
+	var body struct{}
+	value, err := client.cache.get(ctx, "GetUserSettings", body, nil, func() (interface{}, error) {
+		var result SettingsResult
+		err := client.callCloud9(ctx, "GetUserSettings", body, &result)
+		return result.Settings, err
+	})
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	if res.StatusCode == 400 {
-		var error AWSError
-		if err = json.Unmarshal(bodyBytes, &error); err != nil {
-			return err
-		}
-		return errors.New(fmt.Sprintf("An error occured of type %s: %s", error.ExceptionType, error.Message))
-	}
+	return value.(string), nil
+}
 
-	return nil
+func (client *AWSCloud9Client) UpdateUserSettings(ctx context.Context, settings string) error {
+	return client.callCloud9(ctx, "UpdateUserSettings", UpdateUserSettingsRequest{
+		Settings: settings,
+	}, nil)
 }
 
-func (client *AWSCloud9Client) CreateEnvironmentSSH(request *CreateEnvironmentSSHRequest) (*CreateEnvironmentSSHResult, error) {
-	res, err := client.executeCloud9("CreateEnvironmentSSH", request)
-	if err != nil {
-		return nil, err
+// GetEnvironmentSettings returns the project settings of an environment, a
+// JSON document.
+func (client *AWSCloud9Client) GetEnvironmentSettings(ctx context.Context, environmentId string) (string, error) {
+	request := GetEnvironmentSettingsRequest{
+		EnvironmentId: environmentId,
 	}
-	bodyBytes, err := io.ReadAll(res.Body)
-	defer res.Body.Close()
+
+	value, err := client.cache.get(ctx, "GetEnvironmentSettings", request, []string{environmentId}, func() (interface{}, error) {
+		var result SettingsResult
+		err := client.callCloud9(ctx, "GetEnvironmentSettings", request, &result)
+		return result.Settings, err
+	})
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	if res.StatusCode == 400 {
-		var error AWSError
-		if err = json.Unmarshal(bodyBytes, &error); err != nil {
-			return nil, err
-		}
-		return nil, errors.New(fmt.Sprintf("An error occured of type %s: %s", error.ExceptionType, error.Message))
-	}
+	return value.(string), nil
+}
+
+func (client *AWSCloud9Client) UpdateEnvironmentSettings(ctx context.Context, environmentId string, settings string) error {
+	return client.callCloud9(ctx, "UpdateEnvironmentSettings", UpdateEnvironmentSettingsRequest{
+		EnvironmentId: environmentId,
+		Settings:      settings,
+	}, nil)
+}
+
+func (client *AWSCloud9Client) CreateEnvironmentSSH(ctx context.Context, request *CreateEnvironmentSSHRequest) (*CreateEnvironmentSSHResult, error) {
 	var result CreateEnvironmentSSHResult
-	if err = json.Unmarshal(bodyBytes, &result); err != nil {
+	if err := client.callCloud9(ctx, "CreateEnvironmentSSH", request, &result); err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
 
-func (client *AWSCloud9Client) GetMemberShips(environmentId string) ([]Cloud9EnvironmentMembership, error) {
+func (client *AWSCloud9Client) GetMemberShips(ctx context.Context, environmentId string) ([]Cloud9EnvironmentMembership, error) {
+	value, err := client.cache.get(ctx, "DescribeEnvironmentMemberships", environmentId, []string{environmentId}, func() (interface{}, error) {
+		return client.describeMemberships(ctx, environmentId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]Cloud9EnvironmentMembership{}, value.([]Cloud9EnvironmentMembership)...), nil
+}
+
+func (client *AWSCloud9Client) describeMemberships(ctx context.Context, environmentId string) ([]Cloud9EnvironmentMembership, error) {
 
 	input := &cloud9.DescribeEnvironmentMembershipsInput{
 		EnvironmentId: aws.String(environmentId),
@@ -201,9 +319,9 @@ func (client *AWSCloud9Client) GetMemberShips(environmentId string) ([]Cloud9Env
 
 	var hasResults bool = true
 	for hasResults {
-		response, err := client.Cloud9.DescribeEnvironmentMemberships(input)
+		response, err := client.Cloud9.DescribeEnvironmentMembershipsWithContext(ctx, input)
 		if err != nil {
-			return nil, err
+			return nil, wrapError(err)
 		}
 
 		for _, membership := range response.Memberships {
@@ -212,6 +330,7 @@ func (client *AWSCloud9Client) GetMemberShips(environmentId string) ([]Cloud9Env
 				Permissions:   *membership.Permissions,
 				UserARN:       *membership.UserArn,
 				UserID:        *membership.UserId,
+				LastAccess:    membership.LastAccess,
 			})
 		}
 
@@ -225,8 +344,47 @@ func (client *AWSCloud9Client) GetMemberShips(environmentId string) ([]Cloud9Env
 	return res, nil
 }
 
-func (client *AWSCloud9Client) GetSSHEnvironments(envIds ...string) ([]Cloud9SSHEnvironment, error) {
-	var res []Cloud9SSHEnvironment = make([]Cloud9SSHEnvironment, 0, len(envIds))
+// ListEnvironments returns the ids of every environment of the account.
+func (client *AWSCloud9Client) ListEnvironments(ctx context.Context) ([]string, error) {
+	var body struct{}
+	value, err := client.cache.get(ctx, "ListEnvironments", body, nil, func() (interface{}, error) {
+		return client.listEnvironments(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]string{}, value.([]string)...), nil
+}
+
+func (client *AWSCloud9Client) listEnvironments(ctx context.Context) ([]string, error) {
+	input := &cloud9.ListEnvironmentsInput{
+		MaxResults: aws.Int64(MAX_RESULTS),
+	}
+
+	var res []string = make([]string, 0)
+
+	var hasResults bool = true
+	for hasResults {
+		response, err := client.Cloud9.ListEnvironmentsWithContext(ctx, input)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+
+		res = append(res, aws.StringValueSlice(response.EnvironmentIds)...)
+
+		if response.NextToken != nil {
+			input.NextToken = response.NextToken
+		} else {
+			hasResults = false
+		}
+	}
+
+	return res, nil
+}
+
+func (client *AWSCloud9Client) describeEnvironments(ctx context.Context, envIds []string) ([]*cloud9.Environment, error) {
+	var res []*cloud9.Environment = make([]*cloud9.Environment, 0, len(envIds))
 	cursor := 0
 	for ; cursor < len(envIds); cursor += MAX_RESULTS {
 		var ids []*string
@@ -240,66 +398,268 @@ func (client *AWSCloud9Client) GetSSHEnvironments(envIds ...string) ([]Cloud9SSH
 			ids[i] = &envIds[cursor+i]
 		}
 
-		response, err := client.Cloud9.DescribeEnvironments(&cloud9.DescribeEnvironmentsInput{
-			EnvironmentIds: ids,
+		batch := aws.StringValueSlice(ids)
+		value, err := client.cache.get(ctx, "DescribeEnvironments", batch, batch, func() (interface{}, error) {
+			response, err := client.Cloud9.DescribeEnvironmentsWithContext(ctx, &cloud9.DescribeEnvironmentsInput{
+				EnvironmentIds: ids,
+			})
+			if err != nil {
+				return nil, wrapError(err)
+			}
+			return response.Environments, nil
 		})
-
 		if err != nil {
 			return nil, err
 		}
 
-		for _, env := range response.Environments {
+		res = append(res, value.([]*cloud9.Environment)...)
+	}
 
-			envId := *env.Id
-			sshConfig, err := client.DescribeSSHRemote(envId)
-			if err != nil {
+	return res, nil
+}
+
+func (client *AWSCloud9Client) GetTags(ctx context.Context, arn string) ([]Tag, error) {
+	return client.getTags(ctx, arn, nil)
+}
+
+// getTags waits for throttle like describeSSHRemote.
+func (client *AWSCloud9Client) getTags(ctx context.Context, arn string, throttle func(ctx context.Context) error) ([]Tag, error) {
+	value, err := client.cache.get(ctx, "ListTagsForResource", arn, []string{environmentIdFromArn(arn)}, func() (interface{}, error) {
+		if throttle != nil {
+			if err := throttle(ctx); err != nil {
 				return nil, err
 			}
+		}
+		return client.listTags(ctx, arn)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-			tags, err := client.Cloud9.ListTagsForResource(&cloud9.ListTagsForResourceInput{
-				ResourceARN: env.Arn,
-			})
+	return append([]Tag{}, value.([]Tag)...), nil
+}
 
-			if err != nil {
-				return nil, err
+func (client *AWSCloud9Client) listTags(ctx context.Context, arn string) ([]Tag, error) {
+	tags, err := client.Cloud9.ListTagsForResourceWithContext(ctx, &cloud9.ListTagsForResourceInput{
+		ResourceARN: aws.String(arn),
+	})
+
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	res := make([]Tag, 0, len(tags.Tags))
+	for _, tag := range tags.Tags {
+		res = append(res, Tag{
+			Key:   *tag.Key,
+			Value: *tag.Value,
+		})
+	}
+
+	return client.options.Tags.RemoveIgnored(res), nil
+}
+
+// TagsConfig returns the provider-level tag settings of the client.
+func (client *AWSCloud9Client) TagsConfig() *TagsConfig {
+	return &client.options.Tags
+}
+
+func (client *AWSCloud9Client) UpdateTags(ctx context.Context, arn string, removedKeys []string, addedTags []Tag) error {
+	if len(removedKeys) > 0 {
+		_, err := client.Cloud9.UntagResourceWithContext(ctx, &cloud9.UntagResourceInput{
+			ResourceARN: aws.String(arn),
+			TagKeys:     aws.StringSlice(removedKeys),
+		})
+		if err != nil {
+			return wrapError(err)
+		}
+	}
+
+	if len(addedTags) > 0 {
+		tags := make([]*cloud9.Tag, len(addedTags))
+		for i, tag := range addedTags {
+			tags[i] = &cloud9.Tag{
+				Key:   aws.String(tag.Key),
+				Value: aws.String(tag.Value),
 			}
+		}
+		_, err := client.Cloud9.TagResourceWithContext(ctx, &cloud9.TagResourceInput{
+			ResourceARN: aws.String(arn),
+			Tags:        tags,
+		})
+		if err != nil {
+			return wrapError(err)
+		}
+	}
+
+	return nil
+}
+
+// GetSSHEnvironments describes the environments along with their tags and,
+// for SSH environments, their remote. The environments are described
+// concurrently, those which could not be are reported in a
+// *PartialResultsError returned along with the others. Environments deleted
+// in the meantime are left out.
+func (client *AWSCloud9Client) GetSSHEnvironments(ctx context.Context, envIds ...string) ([]Cloud9SSHEnvironment, error) {
+	environments, err := client.DescribeEnvironments(ctx, envIds...)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.HydrateSSHEnvironments(ctx, environments)
+}
+
+// DescribeEnvironments only describes the environments in batches, their
+// remote and tags are left empty. It is used to filter the environments on
+// their name, type or owner before hydrating them.
+func (client *AWSCloud9Client) DescribeEnvironments(ctx context.Context, envIds ...string) ([]Cloud9SSHEnvironment, error) {
+	environments, err := client.describeEnvironments(ctx, envIds)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Cloud9SSHEnvironment, len(environments))
+	for i, env := range environments {
+		res[i] = Cloud9SSHEnvironment{
+			Arn:           *env.Arn,
+			EnvironmentId: *env.Id,
+			Name:          aws.StringValue(env.Name),
+			Description:   aws.StringValue(env.Description),
+			Type:          aws.StringValue(env.Type),
+			OwnerArn:      aws.StringValue(env.OwnerArn),
+		}
+	}
+	return res, nil
+}
 
-			tagMap := make([]Tag, 0)
-			for _, tag := range tags.Tags {
-				tagMap = append(tagMap, Tag{
-					Key:   *tag.Key,
-					Value: *tag.Value,
-				})
+// HydrateSSHEnvironments adds the tags and, for SSH environments, the remote
+// to environments returned by DescribeEnvironments, like
+// GetSSHEnvironments.
+func (client *AWSCloud9Client) HydrateSSHEnvironments(ctx context.Context, environments []Cloud9SSHEnvironment) ([]Cloud9SSHEnvironment, error) {
+	hydrated := make([]Cloud9SSHEnvironment, len(environments))
+	errs := client.forEachEnvironment(ctx, len(environments), func(ctx context.Context, i int) error {
+		environment := environments[i]
+
+		// only ssh environments have a remote to describe
+		if environment.Type == cloud9.EnvironmentTypeSsh {
+			sshConfig, err := client.describeSSHRemote(ctx, environment.EnvironmentId, client.limiter.Wait)
+			if err != nil {
+				return err
 			}
 
-			res = append(res, Cloud9SSHEnvironment{
-				Arn:             *env.Arn,
-				EnvironmentId:   envId,
-				Name:            *env.Name,
-				Description:     *env.Description,
-				EnvironmentPath: sshConfig.Results.EnvironmentPath,
-				Hostname:        sshConfig.Results.Hostname,
-				LoginName:       sshConfig.Results.LoginName,
-				Port:            sshConfig.Results.Port,
-				NodePath:        sshConfig.Results.NodePath,
-				BastionHost:     sshConfig.Results.BastionHost,
-				Tags:            tagMap,
-			})
+			environment.EnvironmentPath = sshConfig.Results.EnvironmentPath
+			environment.Hostname = sshConfig.Results.Hostname
+			environment.LoginName = sshConfig.Results.LoginName
+			environment.Port = sshConfig.Results.Port
+			environment.NodePath = sshConfig.Results.NodePath
+			environment.BastionHost = sshConfig.Results.BastionHost
+		}
+
+		tags, err := client.getTags(ctx, environment.Arn, client.limiter.Wait)
+		if err != nil {
+			return err
 		}
+		environment.Tags = tags
+
+		hydrated[i] = environment
+		return nil
+	})
+
+	indexes, err := describedIndexes(ctx, errs, func(i int) string {
+		return environments[i].EnvironmentId
+	})
+	if indexes == nil {
+		return nil, err
 	}
 
-	return res, nil
+	res := make([]Cloud9SSHEnvironment, 0, len(indexes))
+	for _, i := range indexes {
+		res = append(res, hydrated[i])
+	}
+	return res, err
+}
+
+// GetEC2Environments describes the environments along with their tags, like
+// GetSSHEnvironments.
+func (client *AWSCloud9Client) GetEC2Environments(ctx context.Context, envIds ...string) ([]Cloud9EC2Environment, error) {
+	environments, err := client.describeEnvironments(ctx, envIds)
+	if err != nil {
+		return nil, err
+	}
+
+	hydrated := make([]Cloud9EC2Environment, len(environments))
+	errs := client.forEachEnvironment(ctx, len(environments), func(ctx context.Context, i int) error {
+		env := environments[i]
+		tags, err := client.getTags(ctx, *env.Arn, client.limiter.Wait)
+		if err != nil {
+			return err
+		}
+
+		hydrated[i] = Cloud9EC2Environment{
+			Arn:            *env.Arn,
+			EnvironmentId:  *env.Id,
+			Name:           aws.StringValue(env.Name),
+			Description:    aws.StringValue(env.Description),
+			ConnectionType: aws.StringValue(env.ConnectionType),
+			Type:           aws.StringValue(env.Type),
+			OwnerArn:       aws.StringValue(env.OwnerArn),
+			Tags:           tags,
+		}
+		return nil
+	})
+
+	indexes, err := describedIndexes(ctx, errs, func(i int) string {
+		return *environments[i].Id
+	})
+	if indexes == nil {
+		return nil, err
+	}
+
+	res := make([]Cloud9EC2Environment, 0, len(indexes))
+	for _, i := range indexes {
+		res = append(res, hydrated[i])
+	}
+	return res, err
+}
+
+func (client *AWSCloud9Client) CreateEnvironmentEC2(ctx context.Context, input *cloud9.CreateEnvironmentEC2Input) (string, error) {
+	response, err := client.Cloud9.CreateEnvironmentEC2WithContext(ctx, input)
+	if err != nil {
+		return "", wrapError(err)
+	}
+
+	return *response.EnvironmentId, nil
+}
+
+// UpdateEC2Environment sets the name and the description of an EC2
+// environment, its instance settings can not be updated.
+func (client *AWSCloud9Client) UpdateEC2Environment(ctx context.Context, env Cloud9EC2Environment) error {
+	_, err := client.Cloud9.UpdateEnvironmentWithContext(ctx, &cloud9.UpdateEnvironmentInput{
+		EnvironmentId: &env.EnvironmentId,
+		Name:          &env.Name,
+		Description:   &env.Description,
+	})
+	return wrapError(err)
 }
 
-func (client *AWSCloud9Client) UpdateEnvironment(env Cloud9SSHEnvironment) error {
-	_, err := client.Cloud9.UpdateEnvironment(&cloud9.UpdateEnvironmentInput{
+// DeleteEnvironment starts the deletion of an environment, see
+// WaitEnvironmentDeleted.
+func (client *AWSCloud9Client) DeleteEnvironment(ctx context.Context, environmentId string) error {
+	_, err := client.Cloud9.DeleteEnvironmentWithContext(ctx, &cloud9.DeleteEnvironmentInput{
+		EnvironmentId: &environmentId,
+	})
+	return wrapError(err)
+}
+
+func (client *AWSCloud9Client) UpdateEnvironment(ctx context.Context, env Cloud9SSHEnvironment) error {
+	_, err := client.Cloud9.UpdateEnvironmentWithContext(ctx, &cloud9.UpdateEnvironmentInput{
 		EnvironmentId: &env.EnvironmentId,
 		Name:          &env.Name,
 		Description:   &env.Description,
 	})
 
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 
 	var updateRequest UpdateSSHRemoteRequest
@@ -311,7 +671,7 @@ func (client *AWSCloud9Client) UpdateEnvironment(env Cloud9SSHEnvironment) error
 	updateRequest.Port = env.Port
 	updateRequest.EnvironmentPath = env.EnvironmentPath
 
-	err = client.UpdateSSHRemote(&updateRequest)
+	err = client.UpdateSSHRemote(ctx, &updateRequest)
 	if err != nil {
 		return err
 	}
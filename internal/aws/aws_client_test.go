@@ -2,26 +2,299 @@ package aws
 
 import (
 	"context"
-	"os"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
 )
 
-var access_key_id = os.Getenv("AWS_ACCESS_KEY_ID")
-var secret_access_key = os.Getenv("AWS_SECRET_ACCESS_KEY")
+func newTestClient(t *testing.T) (*AWSCloud9Client, *fakecloud9.Server) {
+	server := fakecloud9.NewServer()
+	t.Cleanup(server.Close)
 
-const region = "eu-west-3"
+	sess := session.Must(session.NewSession(aws.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials("AKIDTEST", "secret", "")).
+		WithRegion(server.Region)))
+	return New(sess, ClientOptions{
+		Endpoint:      server.URL,
+		MaxRetries:    3,
+		MinRetryDelay: time.Millisecond,
+		MaxRetryDelay: 5 * time.Millisecond,
+		WaiterDelay:   time.Millisecond,
+		// the rate limiting is tested on its own
+		RequestsPerSecond: 1000,
+	}), server
+}
+
+func TestSSHEnvironment(t *testing.T) {
+	client, server := newTestClient(t)
 
-func TestRequest(t *testing.T) {
+	created, err := client.CreateEnvironmentSSH(context.Background(), &CreateEnvironmentSSHRequest{
+		Name:        "env",
+		Description: "an environment",
+		LoginName:   "ubuntu",
+		Hostname:    "example.com",
+		Port:        2222,
+		BastionHost: "jump@bastion.example.com:22",
+		Tags:        []Tag{{Key: "team", Value: "infra"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateEnvironmentSSH: %s", err)
+	}
 
-	client := New(context.Background(), credentials.NewStaticCredentials(access_key_id, secret_access_key, ""), region)
-	// environment, err := client.DescribeSSHRemote("573a64362bc44311a52fa6e0178b3dd3")
-	envs, err := client.GetSSHEnvironments([]string{"573a64362bc44311a52fa6e0178b3dd3"})
+	envs, err := client.GetSSHEnvironments(context.Background(), created.EnvironmentId)
 	if err != nil {
-		t.Fatalf("error: %s", err)
+		t.Fatalf("GetSSHEnvironments: %s", err)
+	}
+	if len(envs) != 1 {
+		t.Fatalf("expected 1 environment, got %d", len(envs))
 	}
 
 	env := envs[0]
-	t.Fatalf("found name: %s => %s@%s", env.Name, env.LoginName, env.Hostname)
+	if env.Name != "env" || env.Description != "an environment" || env.LoginName != "ubuntu" ||
+		env.Hostname != "example.com" || env.Port != 2222 || env.BastionHost != "jump@bastion.example.com:22" {
+		t.Errorf("unexpected environment %+v", env)
+	}
+	if env.EnvironmentPath != fakecloud9.DEFAULT_ENVIRONMENT_PATH || env.NodePath != fakecloud9.DEFAULT_NODE_PATH {
+		t.Errorf("unexpected paths %s %s", env.EnvironmentPath, env.NodePath)
+	}
+	if len(env.Tags) != 1 || env.Tags[0].Key != "team" || env.Tags[0].Value != "infra" {
+		t.Errorf("unexpected tags %v", env.Tags)
+	}
+
+	env.Name = "renamed"
+	env.Hostname = "other.example.com"
+	if err = client.UpdateEnvironment(context.Background(), env); err != nil {
+		t.Fatalf("UpdateEnvironment: %s", err)
+	}
+	updated := server.Environment(created.EnvironmentId)
+	if updated.Name != "renamed" || updated.Remote.Hostname != "other.example.com" {
+		t.Errorf("environment was not updated: %+v", updated)
+	}
+
+	if err = client.UpdateTags(context.Background(), env.Arn, []string{"team"}, []Tag{{Key: "owner", Value: "me"}}); err != nil {
+		t.Fatalf("UpdateTags: %s", err)
+	}
+	if tags := server.Tags(created.EnvironmentId); len(tags) != 1 || tags["owner"] != "me" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
+
+func TestEC2Environment(t *testing.T) {
+	client, server := newTestClient(t)
+	envId := server.AddEC2Environment("env", nil)
+
+	err := client.UpdateEC2Environment(context.Background(), Cloud9EC2Environment{
+		EnvironmentId: envId,
+		Name:          "renamed",
+		Description:   "an environment",
+	})
+	if err != nil {
+		t.Fatalf("UpdateEC2Environment: %s", err)
+	}
+	if updated := server.Environment(envId); updated.Name != "renamed" || updated.Description != "an environment" {
+		t.Errorf("environment was not updated: %+v", updated)
+	}
+
+	if err = client.DeleteEnvironment(context.Background(), envId); err != nil {
+		t.Fatalf("DeleteEnvironment: %s", err)
+	}
+	if err = client.WaitEnvironmentDeleted(context.Background(), envId); err != nil {
+		t.Fatalf("WaitEnvironmentDeleted: %s", err)
+	}
+	if err = client.DeleteEnvironment(context.Background(), envId); !IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if _, ok := AsCloud9Error(err); !ok {
+		t.Errorf("expected a *Cloud9Error, got %T", err)
+	}
+}
+
+func TestGetSSHEnvironmentsBatches(t *testing.T) {
+	client, server := newTestClient(t)
+
+	ids := make([]string, 0, MAX_RESULTS+5)
+	for i := 0; i < cap(ids); i++ {
+		ids = append(ids, server.AddSSHEnvironment(fmt.Sprintf("env-%d", i), fakecloud9.SSHRemote{
+			LoginName: "ubuntu",
+			Hostname:  "example.com",
+			Port:      22,
+		}, nil))
+	}
+
+	envs, err := client.GetSSHEnvironments(context.Background(), ids...)
+	if err != nil {
+		t.Fatalf("GetSSHEnvironments: %s", err)
+	}
+	if len(envs) != len(ids) {
+		t.Errorf("expected %d environments, got %d", len(ids), len(envs))
+	}
+	if calls := server.Calls("DescribeEnvironments"); calls != 2 {
+		t.Errorf("expected 2 DescribeEnvironments calls, got %d", calls)
+	}
+}
+
+func TestListEnvironments(t *testing.T) {
+	client, server := newTestClient(t)
+
+	ids := make([]string, 0, MAX_RESULTS+5)
+	for i := 0; i < MAX_RESULTS+4; i++ {
+		ids = append(ids, server.AddSSHEnvironment(fmt.Sprintf("env-%d", i), fakecloud9.SSHRemote{
+			LoginName: "ubuntu",
+			Hostname:  "example.com",
+		}, nil))
+	}
+	ids = append(ids, server.AddEC2Environment("ec2", nil))
+
+	listed, err := client.ListEnvironments(context.Background())
+	if err != nil {
+		t.Fatalf("ListEnvironments: %s", err)
+	}
+	if len(listed) != len(ids) {
+		t.Fatalf("expected %d environments, got %d", len(ids), len(listed))
+	}
+	if calls := server.Calls("ListEnvironments"); calls != 2 {
+		t.Errorf("expected 2 ListEnvironments calls, got %d", calls)
+	}
+
+	envs, err := client.GetSSHEnvironments(context.Background(), listed...)
+	if err != nil {
+		t.Fatalf("GetSSHEnvironments: %s", err)
+	}
+	ec2 := envs[len(envs)-1]
+	if ec2.Type != "ec2" || ec2.OwnerArn != server.CallerArn || len(ec2.Hostname) > 0 {
+		t.Errorf("unexpected ec2 environment %+v", ec2)
+	}
+	if calls := server.Calls("DescribeSSHRemote"); calls != len(ids)-1 {
+		t.Errorf("expected %d DescribeSSHRemote calls, got %d", len(ids)-1, calls)
+	}
+}
+
+func TestMemberships(t *testing.T) {
+	client, server := newTestClient(t)
+
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+	for i := 0; i < MAX_RESULTS+1; i++ {
+		server.AddMembership(envId, fmt.Sprintf("arn:aws:iam::123456789012:user/user-%d", i), READONLY)
+	}
+
+	memberships, err := client.GetMemberShips(context.Background(), envId)
+	if err != nil {
+		t.Fatalf("GetMemberShips: %s", err)
+	}
+	// the owner membership is created along with the environment
+	if len(memberships) != MAX_RESULTS+2 {
+		t.Errorf("expected %d memberships, got %d", MAX_RESULTS+2, len(memberships))
+	}
+	if memberships[0].Permissions != OWNER || memberships[0].UserARN != server.CallerArn {
+		t.Errorf("unexpected owner membership %+v", memberships[0])
+	}
+}
+
+func TestGetUserPublicKey(t *testing.T) {
+	client, server := newTestClient(t)
+
+	key, err := client.GetUserPublicKey(context.Background())
+	if err != nil {
+		t.Fatalf("GetUserPublicKey: %s", err)
+	}
+	if key.PublicKey != server.PublicKey {
+		t.Errorf("unexpected public key %s", key.PublicKey)
+	}
+}
+
+func TestSettings(t *testing.T) {
+	client, server := newTestClient(t)
+
+	if err := client.UpdateUserSettings(context.Background(), `{"ace":{"tabSize":2}}`); err != nil {
+		t.Fatalf("UpdateUserSettings: %s", err)
+	}
+	settings, err := client.GetUserSettings(context.Background())
+	if err != nil {
+		t.Fatalf("GetUserSettings: %s", err)
+	}
+	if settings != `{"ace":{"tabSize":2}}` || server.UserSettings() != settings {
+		t.Errorf("unexpected user settings %s", settings)
+	}
+
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+	if err := client.UpdateEnvironmentSettings(context.Background(), envId, `{"run":{"configs":[]}}`); err != nil {
+		t.Fatalf("UpdateEnvironmentSettings: %s", err)
+	}
+	settings, err = client.GetEnvironmentSettings(context.Background(), envId)
+	if err != nil {
+		t.Fatalf("GetEnvironmentSettings: %s", err)
+	}
+	if settings != `{"run":{"configs":[]}}` {
+		t.Errorf("unexpected environment settings %s", settings)
+	}
+
+	if _, err := client.GetEnvironmentSettings(context.Background(), "missing"); !IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if err := client.UpdateUserSettings(context.Background(), "[]"); err == nil {
+		t.Errorf("expected an error for a settings document which is not an object")
+	}
+}
+
+func TestDescribeSSHRemoteError(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	if _, err := client.DescribeSSHRemote(context.Background(), "missing"); err == nil {
+		t.Errorf("expected an error for a missing environment")
+	}
+}
+
+var credentialPattern = regexp.MustCompile(`Credential=([^/]+)/[0-9]+/([^/]+)/([^/]+)/`)
+
+func TestSigningIdentity(t *testing.T) {
+	var lock sync.Mutex
+	identities := make(map[string]string)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		match := credentialPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+		if match == nil {
+			t.Errorf("unsigned request for %s", r.Header.Get("X-Amz-Target"))
+		} else {
+			identities[r.Header.Get("X-Amz-Target")] = match[1] + "/" + match[2] + "/" + match[3]
+		}
+
+		w.Header().Set("Content-Type", AWS_JSON)
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	sess := session.Must(session.NewSession(aws.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials("AKIDPROVIDER", "secret", "")).
+		WithRegion("eu-west-3").
+		WithEndpoint(server.URL)))
+	client := New(sess, DefaultClientOptions())
+
+	if _, err := client.GetUserPublicKey(context.Background()); err != nil {
+		t.Fatalf("GetUserPublicKey: %s", err)
+	}
+	if _, err := client.GetMemberShips(context.Background(), "env"); err != nil {
+		t.Fatalf("GetMemberShips: %s", err)
+	}
+
+	expected := "AKIDPROVIDER/eu-west-3/cloud9"
+	for _, target := range []string{
+		OPERATION_PREFIX + ".GetUserPublicKey",
+		OPERATION_PREFIX + ".DescribeEnvironmentMemberships",
+	} {
+		if identities[target] != expected {
+			t.Errorf("%s signed with %q, expected %q", target, identities[target], expected)
+		}
+	}
 }
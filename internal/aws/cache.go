@@ -0,0 +1,180 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// readCache holds the results of the read operations for the lifetime of a
+// client, that is a single terraform command. Concurrent identical reads
+// share a single call.
+//
+// Entries are scoped to the environments they describe, a mutating call
+// drops the entries of its environment and the account-wide ones, such as
+// ListEnvironments. Mutating calls without an environment drop every entry.
+type readCache struct {
+	lock    sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	// environments are the ids of the described environments, nil for
+	// account-wide entries.
+	environments []string
+	done         chan struct{}
+	value        interface{}
+	err          error
+}
+
+func newReadCache() *readCache {
+	return &readCache{entries: make(map[string]*cacheEntry)}
+}
+
+func cacheKey(operation string, input interface{}) string {
+	encoded, _ := json.Marshal(input)
+	return operation + ":" + string(encoded)
+}
+
+// get returns the cached result of the operation, or calls fetch. Failed
+// calls are not cached. A nil cache always calls fetch. Waiting for the
+// shared call stops once ctx is done.
+func (c *readCache) get(ctx context.Context, operation string, input interface{}, environments []string, fetch func() (interface{}, error)) (interface{}, error) {
+	if c == nil {
+		return fetch()
+	}
+
+	key := cacheKey(operation, input)
+	c.lock.Lock()
+	if entry, ok := c.entries[key]; ok {
+		c.lock.Unlock()
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if entry.err != nil {
+			// the shared call may have failed because of its own context
+			return fetch()
+		}
+		return entry.value, nil
+	}
+	entry := &cacheEntry{
+		environments: environments,
+		done:         make(chan struct{}),
+	}
+	c.entries[key] = entry
+	c.lock.Unlock()
+
+	entry.value, entry.err = fetch()
+	close(entry.done)
+
+	if entry.err != nil {
+		c.lock.Lock()
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+		c.lock.Unlock()
+	}
+	return entry.value, entry.err
+}
+
+// invalidate drops the entries describing the environment and the
+// account-wide ones, every entry is dropped when environmentId is empty.
+// Reads in flight complete with their result, which is no longer cached.
+func (c *readCache) invalidate(environmentId string) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key, entry := range c.entries {
+		if len(environmentId) == 0 || entry.environments == nil || contains(entry.environments, environmentId) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isMutating reports whether an operation changes the state of the account.
+func isMutating(operation string) bool {
+	for _, prefix := range []string{"Describe", "List", "Get"} {
+		if strings.HasPrefix(operation, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// environmentIdFromArn returns the id of an environment from its ARN,
+// formatted like `arn:aws:cloud9:region:account:environment:id`.
+func environmentIdFromArn(arn string) string {
+	if i := strings.LastIndex(arn, ":environment:"); i >= 0 {
+		return arn[i+len(":environment:"):]
+	}
+	return ""
+}
+
+// environmentIdOf returns the environment an operation input refers to,
+// through its EnvironmentId or ResourceARN field, or an empty string.
+func environmentIdOf(input interface{}) string {
+	value := reflect.ValueOf(input)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return ""
+	}
+
+	for _, name := range []string{"EnvironmentId", "ResourceARN"} {
+		field := value.FieldByName(name)
+		if field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+		if field.Kind() != reflect.String {
+			continue
+		}
+		if name == "ResourceARN" {
+			return environmentIdFromArn(field.String())
+		}
+		return field.String()
+	}
+	return ""
+}
+
+// invalidateAfter drops the cached entries affected by a mutating operation,
+// the environment is read from its input.
+func (c *readCache) invalidateAfter(operation string, input interface{}) {
+	if c != nil && isMutating(operation) {
+		c.invalidate(environmentIdOf(input))
+	}
+}
+
+// sdkInvalidateHandler invalidates the cache once the mutating calls of the
+// SDK client complete, including the ones made on AWSCloud9Client.Cloud9
+// directly.
+func (c *readCache) sdkInvalidateHandler() request.NamedHandler {
+	return request.NamedHandler{
+		Name: "awscloud9.InvalidateReadCache",
+		Fn: func(r *request.Request) {
+			c.invalidateAfter(r.Operation.Name, r.Params)
+		},
+	}
+}
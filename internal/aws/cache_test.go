@@ -0,0 +1,245 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloud9"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func newCachedTestClient(t *testing.T) (*AWSCloud9Client, *fakecloud9.Server) {
+	server := fakecloud9.NewServer()
+	t.Cleanup(server.Close)
+
+	sess := session.Must(session.NewSession(aws.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials("AKIDTEST", "secret", "")).
+		WithRegion(server.Region)))
+	return New(sess, ClientOptions{
+		Endpoint:          server.URL,
+		MaxRetries:        0,
+		RequestsPerSecond: 1000,
+		CacheReads:        true,
+	}), server
+}
+
+func TestCacheMemberships(t *testing.T) {
+	client, server := newCachedTestClient(t)
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+	otherId := server.AddSSHEnvironment("other", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetMemberShips(context.Background(), envId); err != nil {
+				t.Errorf("GetMemberShips: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls := server.Calls("DescribeEnvironmentMemberships"); calls != 1 {
+		t.Fatalf("expected a single DescribeEnvironmentMemberships call, got %d", calls)
+	}
+
+	// the returned slices are not shared
+	memberships, _ := client.GetMemberShips(context.Background(), envId)
+	memberships[0].Permissions = READONLY
+	memberships, _ = client.GetMemberShips(context.Background(), envId)
+	if memberships[0].Permissions != OWNER {
+		t.Errorf("the cached memberships were modified")
+	}
+
+	// a mutation made directly with the SDK client invalidates the
+	// environment only
+	client.GetMemberShips(context.Background(), otherId)
+	_, err := client.Cloud9.CreateEnvironmentMembershipWithContext(context.Background(), &cloud9.CreateEnvironmentMembershipInput{
+		EnvironmentId: aws.String(envId),
+		UserArn:       aws.String("arn:aws:iam::123456789012:user/member"),
+		Permissions:   aws.String(READ_WRITE),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memberships, err = client.GetMemberShips(context.Background(), envId)
+	if err != nil || len(memberships) != 2 {
+		t.Errorf("expected the new membership to be read, got %v: %v", memberships, err)
+	}
+	client.GetMemberShips(context.Background(), otherId)
+	if calls := server.Calls("DescribeEnvironmentMemberships"); calls != 3 {
+		t.Errorf("expected 3 DescribeEnvironmentMemberships calls, got %d", calls)
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	client, server := newCachedTestClient(t)
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+
+	lookup := func() Cloud9SSHEnvironment {
+		t.Helper()
+		envs, err := client.GetSSHEnvironments(context.Background(), envId)
+		if err != nil || len(envs) != 1 {
+			t.Fatalf("GetSSHEnvironments: %d environments, %v", len(envs), err)
+		}
+		return envs[0]
+	}
+
+	env := lookup()
+	lookup()
+	for _, operation := range []string{"DescribeEnvironments", "DescribeSSHRemote", "ListTagsForResource"} {
+		if calls := server.Calls(operation); calls != 1 {
+			t.Errorf("expected a single %s call, got %d", operation, calls)
+		}
+	}
+
+	// executeCloud9 mutation
+	env.Hostname = "other.example.com"
+	if err := client.UpdateEnvironment(context.Background(), env); err != nil {
+		t.Fatal(err)
+	}
+	if env = lookup(); env.Hostname != "other.example.com" {
+		t.Errorf("expected the updated hostname, got %s", env.Hostname)
+	}
+
+	// tags are invalidated through the environment ARN
+	if err := client.UpdateTags(context.Background(), env.Arn, nil, []Tag{{Key: "team", Value: "infra"}}); err != nil {
+		t.Fatal(err)
+	}
+	if env = lookup(); len(env.Tags) != 1 {
+		t.Errorf("expected the new tag, got %v", env.Tags)
+	}
+
+	// account-wide entries are invalidated by any mutation
+	client.ListEnvironments(context.Background())
+	created, err := client.CreateEnvironmentSSH(context.Background(), &CreateEnvironmentSSHRequest{
+		Name:      "created",
+		LoginName: "ubuntu",
+		Hostname:  "example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, _ := client.ListEnvironments(context.Background())
+	if len(ids) != 2 || ids[1] != created.EnvironmentId {
+		t.Errorf("expected the created environment to be listed, got %v", ids)
+	}
+
+	// out-of-band changes are not seen while cached
+	lookup()
+	server.SetTag(envId, "owner", "me")
+	if env = lookup(); len(env.Tags) != 1 {
+		t.Errorf("expected the cached tags, got %v", env.Tags)
+	}
+}
+
+func TestCacheErrors(t *testing.T) {
+	client, server := newCachedTestClient(t)
+
+	server.InjectError("GetUserPublicKey", &fakecloud9.Error{
+		Status:        http.StatusInternalServerError,
+		ExceptionType: "InternalServerErrorException",
+	}, 1)
+	if _, err := client.GetUserPublicKey(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := client.GetUserPublicKey(context.Background()); err != nil {
+		t.Fatalf("expected the error not to be cached, got %s", err)
+	}
+	client.GetUserPublicKey(context.Background())
+	if calls := server.Calls("GetUserPublicKey"); calls != 2 {
+		t.Errorf("expected 2 GetUserPublicKey calls, got %d", calls)
+	}
+}
+
+func TestCacheCancelledWait(t *testing.T) {
+	cache := newReadCache()
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	go cache.get(context.Background(), "GetUserPublicKey", nil, nil, func() (interface{}, error) {
+		close(started)
+		<-release
+		return "key", nil
+	})
+	<-started
+	defer close(release)
+
+	// a cancelled caller does not wait for the shared call
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err := cache.get(ctx, "GetUserPublicKey", nil, nil, func() (interface{}, error) {
+		t.Error("expected the call in flight to be shared")
+		return nil, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancellation error, got %v", err)
+	}
+}
+
+func TestCacheHitsNotThrottled(t *testing.T) {
+	client, server := newCachedTestClient(t)
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+
+	if _, err := client.GetSSHEnvironments(context.Background(), envId); err != nil {
+		t.Fatal(err)
+	}
+	next := client.limiter.next
+	if _, err := client.GetSSHEnvironments(context.Background(), envId); err != nil {
+		t.Fatal(err)
+	}
+	if !client.limiter.next.Equal(next) {
+		t.Errorf("expected the cached lookups not to be rate limited")
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	client, server := newTestClient(t)
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+
+	client.GetMemberShips(context.Background(), envId)
+	client.GetMemberShips(context.Background(), envId)
+	if calls := server.Calls("DescribeEnvironmentMemberships"); calls != 2 {
+		t.Errorf("expected 2 DescribeEnvironmentMemberships calls, got %d", calls)
+	}
+}
+
+func TestCacheEnvironmentStatus(t *testing.T) {
+	client, server := newCachedTestClient(t)
+	server.SetTransitionPolls(2, 0)
+	client.options.WaiterDelay = time.Millisecond
+
+	created, err := client.CreateEnvironmentSSH(context.Background(), &CreateEnvironmentSSHRequest{
+		Name:      "env",
+		LoginName: "ubuntu",
+		Hostname:  "example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.WaitEnvironmentReady(context.Background(), created.EnvironmentId); err != nil {
+		t.Fatalf("expected the status polls not to be cached: %s", err)
+	}
+}
+
+func TestEnvironmentIdOf(t *testing.T) {
+	inputs := map[interface{}]string{
+		&cloud9.DeleteEnvironmentInput{EnvironmentId: aws.String("env")}:                                           "env",
+		&cloud9.TagResourceInput{ResourceARN: aws.String("arn:aws:cloud9:eu-west-3:123456789012:environment:env")}: "env",
+		&UpdateSSHRemoteRequest{EnvironmentId: "env"}:                                                              "env",
+		&CreateEnvironmentSSHRequest{Name: "env"}:                                                                  "",
+		"env": "",
+	}
+	for input, expected := range inputs {
+		if id := environmentIdOf(input); id != expected {
+			t.Errorf("%+v: expected %q, got %q", input, expected, id)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+const DEFAULT_SESSION_NAME = "terraform-provider-awscloud9"
+
+type AssumeRoleConfig struct {
+	RoleARN     string
+	SessionName string
+	ExternalID  string
+	Duration    time.Duration
+}
+
+type WebIdentityConfig struct {
+	RoleARN              string
+	SessionName          string
+	WebIdentityToken     string
+	WebIdentityTokenFile string
+}
+
+// Endpoints overrides the default AWS endpoints of the services used by the
+// provider, empty values keep the default endpoint.
+type Endpoints struct {
+	Cloud9 string
+	STS    string
+}
+
+// Config holds the settings used to resolve the credentials and the region
+// of the provider. Every empty field is resolved through the default AWS
+// credential chain: environment, shared files, web identity and EC2/ECS
+// metadata.
+type Config struct {
+	AccessKeyID            string
+	SecretAccessKey        string
+	SessionToken           string
+	Region                 string
+	Profile                string
+	SharedConfigFiles      []string
+	SharedCredentialsFiles []string
+	AssumeRole             *AssumeRoleConfig
+	WebIdentity            *WebIdentityConfig
+	Endpoints              Endpoints
+}
+
+type webIdentityToken string
+
+func (token webIdentityToken) FetchToken(ctx credentials.Context) ([]byte, error) {
+	return []byte(token), nil
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); len(value) > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+func sessionName(name string) string {
+	if len(name) == 0 {
+		return DEFAULT_SESSION_NAME
+	}
+	return name
+}
+
+// NewSession builds a session from the config and checks that both a region
+// and credentials could be resolved.
+func NewSession(ctx context.Context, config *Config) (*session.Session, error) {
+	awsConfig := aws.NewConfig().WithCredentialsChainVerboseErrors(true)
+	if len(config.Region) > 0 {
+		awsConfig = awsConfig.WithRegion(config.Region)
+	}
+
+	if len(config.AccessKeyID) > 0 || len(config.SecretAccessKey) > 0 {
+		if len(config.AccessKeyID) == 0 || len(config.SecretAccessKey) == 0 {
+			return nil, errors.New("both the access key id and the secret access key must be provided")
+		}
+		awsConfig = awsConfig.WithCredentials(credentials.NewStaticCredentials(
+			config.AccessKeyID, config.SecretAccessKey, config.SessionToken,
+		))
+	}
+
+	options := session.Options{
+		Config:            *awsConfig,
+		Profile:           config.Profile,
+		SharedConfigState: session.SharedConfigEnable,
+	}
+	if len(config.SharedConfigFiles) > 0 || len(config.SharedCredentialsFiles) > 0 {
+		// the files replace the defaults of the SDK, which are kept for the
+		// list which was not configured
+		credentialsFiles := config.SharedCredentialsFiles
+		if len(credentialsFiles) == 0 {
+			credentialsFiles = []string{envOrDefault("AWS_SHARED_CREDENTIALS_FILE", defaults.SharedCredentialsFilename())}
+		}
+		configFiles := config.SharedConfigFiles
+		if len(configFiles) == 0 {
+			configFiles = []string{envOrDefault("AWS_CONFIG_FILE", defaults.SharedConfigFilename())}
+		}
+		options.SharedConfigFiles = append(options.SharedConfigFiles, credentialsFiles...)
+		options.SharedConfigFiles = append(options.SharedConfigFiles, configFiles...)
+	}
+
+	sess, err := session.NewSessionWithOptions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(aws.StringValue(sess.Config.Region)) == 0 {
+		return nil, errors.New("no AWS region could be resolved from the configuration, the environment or the shared config files")
+	}
+
+	stsConfig := aws.NewConfig()
+	if len(config.Endpoints.STS) > 0 {
+		stsConfig = stsConfig.WithEndpoint(config.Endpoints.STS)
+	}
+
+	if config.WebIdentity != nil {
+		var fetcher stscreds.TokenFetcher
+		if len(config.WebIdentity.WebIdentityToken) > 0 {
+			fetcher = webIdentityToken(config.WebIdentity.WebIdentityToken)
+		} else if len(config.WebIdentity.WebIdentityTokenFile) > 0 {
+			fetcher = stscreds.FetchTokenPath(config.WebIdentity.WebIdentityTokenFile)
+		} else {
+			return nil, errors.New("one of web_identity_token and web_identity_token_file must be provided")
+		}
+
+		provider := stscreds.NewWebIdentityRoleProviderWithOptions(sts.New(sess, stsConfig),
+			config.WebIdentity.RoleARN,
+			sessionName(config.WebIdentity.SessionName),
+			fetcher,
+		)
+		sess = sess.Copy(aws.NewConfig().WithCredentials(credentials.NewCredentials(provider)))
+	}
+
+	if config.AssumeRole != nil {
+		creds := stscreds.NewCredentialsWithClient(sts.New(sess, stsConfig), config.AssumeRole.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+			p.RoleSessionName = sessionName(config.AssumeRole.SessionName)
+			if len(config.AssumeRole.ExternalID) > 0 {
+				p.ExternalID = aws.String(config.AssumeRole.ExternalID)
+			}
+			if config.AssumeRole.Duration > 0 {
+				p.Duration = config.AssumeRole.Duration
+			}
+		})
+		sess = sess.Copy(aws.NewConfig().WithCredentials(creds))
+	}
+
+	if _, err = sess.Config.Credentials.GetWithContext(ctx); err != nil {
+		return nil, fmt.Errorf("no valid credential sources found: %w", err)
+	}
+
+	return sess, nil
+}
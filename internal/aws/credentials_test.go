@@ -0,0 +1,209 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func clearAWSEnv(t *testing.T) {
+	for _, key := range []string{
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+		"AWS_REGION", "AWS_DEFAULT_REGION", "AWS_PROFILE",
+		"AWS_CONFIG_FILE", "AWS_SHARED_CREDENTIALS_FILE",
+		"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN",
+	} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+}
+
+func TestNewSessionStaticCredentials(t *testing.T) {
+	clearAWSEnv(t)
+
+	sess, err := NewSession(context.Background(), &Config{
+		AccessKeyID:     "AKIDSTATIC",
+		SecretAccessKey: "secret",
+		SessionToken:    "token",
+		Region:          "eu-west-3",
+	})
+	if err != nil {
+		t.Fatalf("NewSession: %s", err)
+	}
+
+	creds, err := sess.Config.Credentials.Get()
+	if err != nil {
+		t.Fatalf("Credentials: %s", err)
+	}
+	if creds.AccessKeyID != "AKIDSTATIC" || creds.SessionToken != "token" {
+		t.Errorf("unexpected credentials %s/%s", creds.AccessKeyID, creds.SessionToken)
+	}
+	if *sess.Config.Region != "eu-west-3" {
+		t.Errorf("unexpected region %s", *sess.Config.Region)
+	}
+}
+
+func TestNewSessionProfile(t *testing.T) {
+	clearAWSEnv(t)
+
+	dir := t.TempDir()
+	credentialsFile := filepath.Join(dir, "credentials")
+	configFile := filepath.Join(dir, "config")
+	if err := os.WriteFile(credentialsFile, []byte("[team]\naws_access_key_id = AKIDPROFILE\naws_secret_access_key = secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configFile, []byte("[profile team]\nregion = us-west-2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	sess, err := NewSession(context.Background(), &Config{
+		Profile:                "team",
+		SharedConfigFiles:      []string{configFile},
+		SharedCredentialsFiles: []string{credentialsFile},
+	})
+	if err != nil {
+		t.Fatalf("NewSession: %s", err)
+	}
+
+	creds, err := sess.Config.Credentials.Get()
+	if err != nil {
+		t.Fatalf("Credentials: %s", err)
+	}
+	if creds.AccessKeyID != "AKIDPROFILE" {
+		t.Errorf("unexpected access key id %s", creds.AccessKeyID)
+	}
+	if *sess.Config.Region != "us-west-2" {
+		t.Errorf("unexpected region %s", *sess.Config.Region)
+	}
+}
+
+func TestNewSessionDefaultSharedFiles(t *testing.T) {
+	clearAWSEnv(t)
+
+	dir := t.TempDir()
+	credentialsFile := filepath.Join(dir, "credentials")
+	configFile := filepath.Join(dir, "config")
+	if err := os.WriteFile(credentialsFile, []byte("[team]\naws_access_key_id = AKIDPROFILE\naws_secret_access_key = secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(configFile, []byte("[profile team]\nregion = us-west-2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsFile)
+	t.Setenv("AWS_CONFIG_FILE", configFile)
+
+	// each configured list only replaces its own default
+	for name, config := range map[string]*Config{
+		"config files":      {Profile: "team", SharedConfigFiles: []string{configFile}},
+		"credentials files": {Profile: "team", SharedCredentialsFiles: []string{credentialsFile}},
+	} {
+		sess, err := NewSession(context.Background(), config)
+		if err != nil {
+			t.Fatalf("%s: NewSession: %s", name, err)
+		}
+
+		creds, err := sess.Config.Credentials.Get()
+		if err != nil {
+			t.Fatalf("%s: Credentials: %s", name, err)
+		}
+		if creds.AccessKeyID != "AKIDPROFILE" || *sess.Config.Region != "us-west-2" {
+			t.Errorf("%s: unexpected access key id %s and region %s", name, creds.AccessKeyID, *sess.Config.Region)
+		}
+	}
+}
+
+func TestNewSessionErrors(t *testing.T) {
+	clearAWSEnv(t)
+
+	configs := map[string]*Config{
+		"missing region": {
+			AccessKeyID:     "AKIDSTATIC",
+			SecretAccessKey: "secret",
+		},
+		"partial static credentials": {
+			AccessKeyID: "AKIDSTATIC",
+			Region:      "eu-west-3",
+		},
+		"web identity without token": {
+			AccessKeyID:     "AKIDSTATIC",
+			SecretAccessKey: "secret",
+			Region:          "eu-west-3",
+			WebIdentity: &WebIdentityConfig{
+				RoleARN: "arn:aws:iam::123456789012:role/ci",
+			},
+		},
+	}
+
+	for name, config := range configs {
+		if _, err := NewSession(context.Background(), config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+const assumeRoleResponse = `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>ASIAASSUMED</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>token</SessionToken>
+      <Expiration>2100-01-01T00:00:00Z</Expiration>
+    </Credentials>
+    <AssumedRoleUser>
+      <Arn>arn:aws:sts::123456789012:assumed-role/cloud9/terraform</Arn>
+      <AssumedRoleId>AROAEXAMPLE:terraform</AssumedRoleId>
+    </AssumedRoleUser>
+  </AssumeRoleResult>
+  <ResponseMetadata>
+    <RequestId>request</RequestId>
+  </ResponseMetadata>
+</AssumeRoleResponse>`
+
+func TestNewSessionAssumeRoleEndpoint(t *testing.T) {
+	clearAWSEnv(t)
+
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(assumeRoleResponse))
+	}))
+	defer server.Close()
+
+	sess, err := NewSession(context.Background(), &Config{
+		AccessKeyID:     "AKIDSTATIC",
+		SecretAccessKey: "secret",
+		Region:          "eu-west-3",
+		AssumeRole: &AssumeRoleConfig{
+			RoleARN:    "arn:aws:iam::123456789012:role/cloud9",
+			ExternalID: "external",
+			Duration:   time.Hour,
+		},
+		Endpoints: Endpoints{
+			STS: server.URL,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSession: %s", err)
+	}
+
+	if form.Get("Action") != "AssumeRole" || form.Get("RoleArn") != "arn:aws:iam::123456789012:role/cloud9" ||
+		form.Get("ExternalId") != "external" || form.Get("DurationSeconds") != "3600" ||
+		form.Get("RoleSessionName") != DEFAULT_SESSION_NAME {
+		t.Errorf("unexpected AssumeRole request %v", form)
+	}
+
+	creds, err := sess.Config.Credentials.Get()
+	if err != nil {
+		t.Fatalf("Credentials: %s", err)
+	}
+	if creds.AccessKeyID != "ASIAASSUMED" || creds.SessionToken != "token" {
+		t.Errorf("unexpected credentials %s/%s", creds.AccessKeyID, creds.SessionToken)
+	}
+}
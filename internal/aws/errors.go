@@ -0,0 +1,125 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+const (
+	REQUEST_ID_HEADER = "X-Amzn-Requestid"
+	ERROR_TYPE_HEADER = "X-Amzn-Errortype"
+)
+
+// Cloud9Error is returned for every non-2xx response, whether the operation
+// was sent through executeCloud9 or through the SDK client.
+type Cloud9Error struct {
+	ExceptionType string
+	Message       string
+	StatusCode    int
+	RequestID     string
+}
+
+func (e *Cloud9Error) Error() string {
+	res := fmt.Sprintf("%s (status %d", e.ExceptionType, e.StatusCode)
+	if len(e.RequestID) > 0 {
+		res += ", request id " + e.RequestID
+	}
+	res += ")"
+	if len(e.Message) > 0 {
+		res += ": " + e.Message
+	}
+	return res
+}
+
+// exceptionType strips the namespace from types formatted like
+// `com.amazonaws.cloud9#NotFoundException`.
+func exceptionType(value string) string {
+	if i := strings.LastIndex(value, "#"); i >= 0 {
+		value = value[i+1:]
+	}
+	if i := strings.Index(value, ":"); i >= 0 {
+		value = value[:i]
+	}
+	return value
+}
+
+func newResponseError(res *http.Response, body []byte) *Cloud9Error {
+	var awsError AWSError
+	json.Unmarshal(body, &awsError)
+
+	err := &Cloud9Error{
+		ExceptionType: exceptionType(awsError.ExceptionType),
+		Message:       awsError.Message,
+		StatusCode:    res.StatusCode,
+		RequestID:     res.Header.Get(REQUEST_ID_HEADER),
+	}
+	if len(err.ExceptionType) == 0 {
+		err.ExceptionType = exceptionType(res.Header.Get(ERROR_TYPE_HEADER))
+	}
+	if len(err.ExceptionType) == 0 {
+		err.ExceptionType = strings.ReplaceAll(http.StatusText(res.StatusCode), " ", "")
+	}
+	if len(err.Message) == 0 && len(awsError.ExceptionType) == 0 {
+		err.Message = strings.TrimSpace(string(body))
+	}
+	return err
+}
+
+// AsCloud9Error extracts a Cloud9Error from err, converting the request
+// failures of the SDK client.
+func AsCloud9Error(err error) (*Cloud9Error, bool) {
+	var cloud9Err *Cloud9Error
+	if errors.As(err, &cloud9Err) {
+		return cloud9Err, true
+	}
+
+	var requestFailure awserr.RequestFailure
+	if errors.As(err, &requestFailure) {
+		return &Cloud9Error{
+			ExceptionType: exceptionType(requestFailure.Code()),
+			Message:       requestFailure.Message(),
+			StatusCode:    requestFailure.StatusCode(),
+			RequestID:     requestFailure.RequestID(),
+		}, true
+	}
+
+	return nil, false
+}
+
+func isError(err error, statusCode int, exceptionTypes ...string) bool {
+	cloud9Err, ok := AsCloud9Error(err)
+	if !ok {
+		return false
+	}
+	if cloud9Err.StatusCode == statusCode {
+		return true
+	}
+	for _, exceptionType := range exceptionTypes {
+		if cloud9Err.ExceptionType == exceptionType {
+			return true
+		}
+	}
+	return false
+}
+
+func IsNotFound(err error) bool {
+	return isError(err, http.StatusNotFound, "NotFoundException", "ResourceNotFoundException")
+}
+
+func IsThrottling(err error) bool {
+	return isError(err, http.StatusTooManyRequests,
+		"ThrottlingException", "TooManyRequestsException", "RequestLimitExceeded", "Throttling")
+}
+
+func IsConflict(err error) bool {
+	return isError(err, http.StatusConflict, "ConflictException", "ConcurrentAccessException")
+}
+
+func IsAccessDenied(err error) bool {
+	return isError(err, http.StatusForbidden, "AccessDeniedException", "UnrecognizedClientException")
+}
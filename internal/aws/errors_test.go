@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func TestCloud9ErrorStatuses(t *testing.T) {
+	client, server := newTestClient(t)
+
+	server.InjectError("GetUserPublicKey", &fakecloud9.Error{
+		Status:        http.StatusInternalServerError,
+		ExceptionType: "InternalServerErrorException",
+		Message:       "boom",
+	}, 4)
+	server.InjectError("GetUserPublicKey", &fakecloud9.Error{
+		Status:        http.StatusForbidden,
+		ExceptionType: "com.amazonaws.cloud9#AccessDeniedException",
+		Message:       "denied",
+	}, 1)
+
+	_, err := client.GetUserPublicKey(context.Background())
+	cloud9Err, ok := AsCloud9Error(err)
+	if !ok {
+		t.Fatalf("expected a Cloud9Error, got %v", err)
+	}
+	if cloud9Err.StatusCode != http.StatusInternalServerError || cloud9Err.ExceptionType != "InternalServerErrorException" ||
+		cloud9Err.Message != "boom" || len(cloud9Err.RequestID) == 0 {
+		t.Errorf("unexpected error %+v", cloud9Err)
+	}
+
+	_, err = client.GetUserPublicKey(context.Background())
+	if !IsAccessDenied(err) {
+		t.Errorf("expected an access denied error, got %v", err)
+	}
+	if cloud9Err, _ := AsCloud9Error(err); cloud9Err.ExceptionType != "AccessDeniedException" {
+		t.Errorf("expected the exception namespace to be stripped, got %s", cloud9Err.ExceptionType)
+	}
+}
+
+func TestCloud9ErrorHelpers(t *testing.T) {
+	client, server := newTestClient(t)
+
+	// executeCloud9 path
+	if _, err := client.DescribeSSHRemote(context.Background(), "missing"); !IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+
+	// SDK path
+	_, err := client.GetMemberShips(context.Background(), "missing")
+	if !IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	var cloud9Err *Cloud9Error
+	if !errors.As(err, &cloud9Err) {
+		t.Errorf("expected the SDK error to be converted, got %T", err)
+	}
+
+	server.InjectError("DescribeEnvironments", &fakecloud9.Error{
+		Status:        http.StatusBadRequest,
+		ExceptionType: "ConflictException",
+	}, 1)
+	if _, err := client.GetSSHEnvironments(context.Background(), "env"); !IsConflict(err) || IsNotFound(err) {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+}
+
+func TestCloud9ErrorThrottling(t *testing.T) {
+	for _, err := range []error{
+		&Cloud9Error{ExceptionType: "ThrottlingException", StatusCode: http.StatusBadRequest},
+		&Cloud9Error{ExceptionType: "TooManyRequestsException", StatusCode: http.StatusBadRequest},
+		&Cloud9Error{ExceptionType: "Unknown", StatusCode: http.StatusTooManyRequests},
+	} {
+		if !IsThrottling(err) {
+			t.Errorf("expected %v to be a throttling error", err)
+		}
+	}
+
+	if IsThrottling(errors.New("ThrottlingException")) {
+		t.Errorf("untyped errors must not be matched on their message")
+	}
+}
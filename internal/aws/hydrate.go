@@ -0,0 +1,136 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvironmentError is the failure to describe a single environment.
+type EnvironmentError struct {
+	EnvironmentId string
+	Err           error
+}
+
+func (e *EnvironmentError) Error() string {
+	return fmt.Sprintf("environment %s: %s", e.EnvironmentId, e.Err)
+}
+
+func (e *EnvironmentError) Unwrap() error {
+	return e.Err
+}
+
+// PartialResultsError is returned along with the environments which could
+// be described when some of them could not.
+type PartialResultsError struct {
+	Errors []*EnvironmentError
+}
+
+func (e *PartialResultsError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("could not describe %d environments: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// AsPartialResultsError returns the per-environment errors of err, if any.
+func AsPartialResultsError(err error) (*PartialResultsError, bool) {
+	partialErr, ok := err.(*PartialResultsError)
+	return partialErr, ok
+}
+
+// rateLimiter spaces out the calls so that at most requestsPerSecond are
+// sent each second, it is shared by the workers of the client.
+type rateLimiter struct {
+	lock     sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(requestsPerSecond int) *rateLimiter {
+	return &rateLimiter{interval: time.Second / time.Duration(requestsPerSecond)}
+}
+
+// Wait blocks until the next call can be sent.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	l.lock.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.lock.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	return sleep(ctx, delay)
+}
+
+// forEachEnvironment calls fn for each index up to count on at most
+// Concurrency workers. The errors are returned by index, nil when fn
+// succeeded.
+func (client *AWSCloud9Client) forEachEnvironment(ctx context.Context, count int, fn func(ctx context.Context, i int) error) []error {
+	errs := make([]error, count)
+	jobs := make(chan int)
+
+	workers := client.options.Concurrency
+	if workers > count {
+		workers = count
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				errs[i] = fn(ctx, i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return errs
+}
+
+// describedIndexes returns, in order, the indexes of the environments
+// which were described and the errors of the others as a
+// *PartialResultsError. The environments which were not found are skipped
+// and the error of ctx is returned as is once it is done.
+func describedIndexes(ctx context.Context, errs []error, envId func(i int) string) ([]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	res := make([]int, 0, len(errs))
+	var partialErr PartialResultsError
+	for i, err := range errs {
+		if err == nil {
+			res = append(res, i)
+		} else if !IsNotFound(err) {
+			partialErr.Errors = append(partialErr.Errors, &EnvironmentError{
+				EnvironmentId: envId(i),
+				Err:           err,
+			})
+		}
+	}
+
+	if len(partialErr.Errors) > 0 {
+		return res, &partialErr
+	}
+	return res, nil
+}
@@ -0,0 +1,186 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func addTestEnvironments(server *fakecloud9.Server, count int) []string {
+	ids := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		ids = append(ids, server.AddSSHEnvironment(fmt.Sprintf("env-%d", i), fakecloud9.SSHRemote{
+			LoginName: "ubuntu",
+			Hostname:  "example.com",
+		}, nil))
+	}
+	return ids
+}
+
+func TestGetSSHEnvironmentsPartialResults(t *testing.T) {
+	client, server := newTestClient(t)
+	ids := addTestEnvironments(server, 5)
+
+	server.InjectError("ListTagsForResource", &fakecloud9.Error{
+		Status:        http.StatusForbidden,
+		ExceptionType: "AccessDeniedException",
+	}, 2)
+
+	envs, err := client.GetSSHEnvironments(context.Background(), ids...)
+	partialErr, ok := AsPartialResultsError(err)
+	if !ok {
+		t.Fatalf("expected partial results, got %v", err)
+	}
+	if len(envs) != 3 || len(partialErr.Errors) != 2 {
+		t.Fatalf("expected 3 environments and 2 errors, got %d and %v", len(envs), partialErr)
+	}
+	for _, envErr := range partialErr.Errors {
+		if !IsAccessDenied(envErr) || len(envErr.EnvironmentId) == 0 {
+			t.Errorf("unexpected environment error %v", envErr)
+		}
+	}
+
+	// the environments are returned in the order they were described
+	position := make(map[string]int)
+	for i, id := range ids {
+		position[id] = i
+	}
+	for i := 1; i < len(envs); i++ {
+		if position[envs[i-1].EnvironmentId] > position[envs[i].EnvironmentId] {
+			t.Errorf("environments are out of order: %s before %s", envs[i-1].Name, envs[i].Name)
+		}
+	}
+}
+
+func TestGetSSHEnvironmentsDeleted(t *testing.T) {
+	client, server := newTestClient(t)
+	ids := addTestEnvironments(server, 3)
+
+	// an environment is deleted between DescribeEnvironments and its
+	// hydration
+	server.InjectError("DescribeSSHRemote", &fakecloud9.Error{
+		Status:        http.StatusBadRequest,
+		ExceptionType: "NotFoundException",
+	}, 1)
+
+	envs, err := client.GetSSHEnvironments(context.Background(), ids...)
+	if err != nil {
+		t.Fatalf("GetSSHEnvironments: %s", err)
+	}
+	if len(envs) != 2 {
+		t.Errorf("expected the deleted environment to be skipped, got %d environments", len(envs))
+	}
+}
+
+func TestGetSSHEnvironmentsCancelled(t *testing.T) {
+	client, server := newTestClient(t)
+	ids := addTestEnvironments(server, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	envs, err := client.GetSSHEnvironments(ctx, ids...)
+	if err != nil || len(envs) != 3 {
+		t.Fatalf("expected 3 environments, got %d: %v", len(envs), err)
+	}
+
+	// a cancelled lookup fails as a whole
+	cancel()
+	envs, err = client.GetSSHEnvironments(ctx, ids...)
+	if _, ok := AsPartialResultsError(err); err == nil || ok || envs != nil {
+		t.Errorf("expected the lookup to fail, got %d environments: %v", len(envs), err)
+	}
+}
+
+func TestHydrateSSHEnvironments(t *testing.T) {
+	client, server := newTestClient(t)
+	ids := addTestEnvironments(server, 3)
+
+	described, err := client.DescribeEnvironments(context.Background(), ids...)
+	if err != nil || len(described) != 3 {
+		t.Fatalf("expected 3 environments, got %d: %v", len(described), err)
+	}
+	if calls := server.Calls("DescribeSSHRemote") + server.Calls("ListTagsForResource"); calls != 0 {
+		t.Errorf("expected no environment to be hydrated, got %d calls", calls)
+	}
+	if described[0].Name != "env-0" || len(described[0].Hostname) > 0 {
+		t.Errorf("unexpected described environment %+v", described[0])
+	}
+
+	// only the given environments are hydrated
+	envs, err := client.HydrateSSHEnvironments(context.Background(), described[1:2])
+	if err != nil || len(envs) != 1 {
+		t.Fatalf("expected 1 environment, got %d: %v", len(envs), err)
+	}
+	if envs[0].EnvironmentId != ids[1] || envs[0].Hostname != "example.com" {
+		t.Errorf("unexpected hydrated environment %+v", envs[0])
+	}
+	if calls := server.Calls("DescribeSSHRemote"); calls != 1 {
+		t.Errorf("expected 1 DescribeSSHRemote call, got %d", calls)
+	}
+	if calls := server.Calls("ListTagsForResource"); calls != 1 {
+		t.Errorf("expected 1 ListTagsForResource call, got %d", calls)
+	}
+}
+
+func TestForEachEnvironmentConcurrency(t *testing.T) {
+	client := &AWSCloud9Client{options: ClientOptions{Concurrency: 3}}
+
+	var lock sync.Mutex
+	running, maxRunning := 0, 0
+	errs := client.forEachEnvironment(context.Background(), 20, func(ctx context.Context, i int) error {
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+
+		if i%2 == 0 {
+			return fmt.Errorf("failed %d", i)
+		}
+		return nil
+	})
+
+	if maxRunning != 3 {
+		t.Errorf("expected 3 concurrent calls, got %d", maxRunning)
+	}
+	for i, err := range errs {
+		if (i%2 == 0) != (err != nil) {
+			t.Errorf("unexpected error for %d: %v", i, err)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(100)
+
+	start := time.Now()
+	for i := 0; i < 11; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected 11 calls to take at least 100ms, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 100; i++ {
+		limiter.Wait(context.Background())
+	}
+	if err := limiter.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the context error, got %v", err)
+	}
+}
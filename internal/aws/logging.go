@@ -0,0 +1,200 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// LOG_SUBSYSTEM is the tflog subsystem of the cloud9 calls, its level can
+// be set with TF_LOG_PROVIDER_AWSCLOUD9_CLOUD9.
+const LOG_SUBSYSTEM = "cloud9"
+
+const REDACTED = "***"
+
+// sensitiveHeaders are never logged, whatever the level.
+var sensitiveHeaders = []string{"Authorization", "X-Amz-Security-Token", "Cookie", "Set-Cookie"}
+
+// sensitiveKeyPattern matches the JSON keys whose values are redacted from
+// the logged bodies.
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)(secret|password|token|credential|authorization|private)`)
+
+// accessKeyPattern matches AWS access key ids, which are masked from every
+// logged field.
+var accessKeyPattern = regexp.MustCompile(`\b(AKIA|ASIA)[A-Z0-9]{16}\b`)
+
+// logContext returns ctx with the cloud9 subsystem logger. It is a no-op
+// when ctx has no provider logger, such as in unit tests.
+func logContext(ctx context.Context) context.Context {
+	ctx = tflog.NewSubsystem(ctx, LOG_SUBSYSTEM,
+		tflog.WithLevelFromEnv("TF_LOG_PROVIDER_AWSCLOUD9", "CLOUD9"),
+		tflog.WithRootFields(),
+	)
+	return tflog.SubsystemMaskAllFieldValuesRegexes(ctx, LOG_SUBSYSTEM, accessKeyPattern)
+}
+
+// callLog describes a call once done, retries included.
+type callLog struct {
+	Target        string
+	EnvironmentId string
+	Start         time.Time
+	Retries       int
+	StatusCode    int
+	RequestID     string
+	Err           error
+}
+
+func (client *AWSCloud9Client) logCall(ctx context.Context, call *callLog) {
+	fields := map[string]interface{}{
+		"target":      call.Target,
+		"latency_ms":  time.Since(call.Start).Milliseconds(),
+		"retries":     call.Retries,
+		"status_code": call.StatusCode,
+	}
+	if len(call.EnvironmentId) > 0 {
+		fields["environment_id"] = call.EnvironmentId
+	}
+	if len(call.RequestID) > 0 {
+		fields["request_id"] = call.RequestID
+	}
+
+	if call.Err != nil {
+		fields["error"] = call.Err.Error()
+		tflog.SubsystemDebug(logContext(ctx), LOG_SUBSYSTEM, "Cloud9 call failed", fields)
+		return
+	}
+	tflog.SubsystemDebug(logContext(ctx), LOG_SUBSYSTEM, "Cloud9 call", fields)
+}
+
+// logAttempt logs the headers of an attempt at TRACE level, along with the
+// bodies when ClientOptions.LogBodies is set. Both are sanitized.
+func (client *AWSCloud9Client) logAttempt(ctx context.Context, target string, attempt int, req *http.Request, res *http.Response, requestBody, responseBody []byte) {
+	fields := map[string]interface{}{
+		"target":  target,
+		"attempt": attempt,
+	}
+	if req != nil {
+		fields["request_headers"] = sanitizeHeaders(req.Header)
+	}
+	if res != nil {
+		fields["status_code"] = res.StatusCode
+		fields["response_headers"] = sanitizeHeaders(res.Header)
+	}
+	if client.options.LogBodies {
+		fields["request_body"] = sanitizeBody(requestBody)
+		if responseBody != nil {
+			fields["response_body"] = sanitizeBody(responseBody)
+		}
+	}
+
+	tflog.SubsystemTrace(logContext(ctx), LOG_SUBSYSTEM, "Cloud9 call attempt", fields)
+}
+
+// sanitizeHeaders flattens the headers, redacting the credentials.
+func sanitizeHeaders(headers http.Header) map[string]string {
+	res := make(map[string]string, len(headers))
+	for key, values := range headers {
+		res[key] = strings.Join(values, ", ")
+	}
+	for _, key := range sensitiveHeaders {
+		if _, ok := res[http.CanonicalHeaderKey(key)]; ok {
+			res[http.CanonicalHeaderKey(key)] = REDACTED
+		}
+	}
+	return res
+}
+
+// sanitizeBody redacts the values of the sensitive keys of a JSON body,
+// including in the JSON documents embedded in strings, such as settings.
+// Other bodies are only described by their size.
+func sanitizeBody(body []byte) string {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return fmt.Sprintf("(%d bytes, not JSON)", len(body))
+	}
+
+	res, err := json.Marshal(sanitizeValue(document))
+	if err != nil {
+		return fmt.Sprintf("(%d bytes)", len(body))
+	}
+	return string(res)
+}
+
+func sanitizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveKeyPattern.MatchString(key) {
+				v[key] = REDACTED
+			} else {
+				v[key] = sanitizeValue(field)
+			}
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = sanitizeValue(v[i])
+		}
+		return v
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(v), "{") {
+			var document interface{}
+			if json.Unmarshal([]byte(v), &document) == nil {
+				encoded, _ := json.Marshal(sanitizeValue(document))
+				return string(encoded)
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// sdkLogHandlers log the calls made through the SDK client, including the
+// ones made on AWSCloud9Client.Cloud9 directly, like callCloud9 does.
+func (client *AWSCloud9Client) sdkLogHandlers() (attempt request.NamedHandler, complete request.NamedHandler) {
+	attempt = request.NamedHandler{
+		Name: "awscloud9.LogAttempt",
+		Fn: func(r *request.Request) {
+			var requestBody, responseBody []byte
+			if client.options.LogBodies {
+				requestBody, _ = json.Marshal(r.Params)
+				if r.Error == nil {
+					responseBody, _ = json.Marshal(r.Data)
+				}
+			}
+			client.logAttempt(r.Context(), sdkTarget(r), r.RetryCount, r.HTTPRequest, r.HTTPResponse, requestBody, responseBody)
+		},
+	}
+	complete = request.NamedHandler{
+		Name: "awscloud9.LogCall",
+		Fn: func(r *request.Request) {
+			call := &callLog{
+				Target:        sdkTarget(r),
+				EnvironmentId: environmentIdOf(r.Params),
+				Start:         r.Time,
+				Retries:       r.RetryCount,
+				RequestID:     r.RequestID,
+				Err:           wrapError(r.Error),
+			}
+			if r.HTTPResponse != nil {
+				call.StatusCode = r.HTTPResponse.StatusCode
+			}
+			client.logCall(r.Context(), call)
+		},
+	}
+	return attempt, complete
+}
+
+// sdkTarget returns the X-Amz-Target of an SDK call, the operations of both
+// paths are logged the same way.
+func sdkTarget(r *request.Request) string {
+	return OPERATION_PREFIX + "." + r.Operation.Name
+}
@@ -0,0 +1,171 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func decodeLogs(t *testing.T, output *bytes.Buffer, message string) []map[string]interface{} {
+	entries, err := tflogtest.MultilineJSONDecode(output)
+	if err != nil {
+		t.Fatalf("could not decode the logs: %s", err)
+	}
+
+	var res []map[string]interface{}
+	for _, entry := range entries {
+		if entry["@message"] == message {
+			res = append(res, entry)
+		}
+	}
+	return res
+}
+
+func createTestEnvironment(t *testing.T, client *AWSCloud9Client) *CreateEnvironmentSSHResult {
+	created, err := client.CreateEnvironmentSSH(context.Background(), &CreateEnvironmentSSHRequest{
+		Name:      "env",
+		LoginName: "ubuntu",
+		Hostname:  "example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return created
+}
+
+func TestLogCalls(t *testing.T) {
+	client, server := newTestClient(t)
+	env := createTestEnvironment(t, client)
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+
+	server.InjectError("DescribeSSHRemote", &fakecloud9.Error{
+		Status:        http.StatusInternalServerError,
+		ExceptionType: "InternalServerErrorException",
+	}, 1)
+	if _, err := client.DescribeSSHRemote(ctx, env.EnvironmentId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetMemberShips(ctx, "missing"); !IsNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls []map[string]interface{}
+	for _, entry := range entries {
+		if entry["@module"] != "provider."+LOG_SUBSYSTEM {
+			t.Errorf("unexpected module %v", entry["@module"])
+		}
+		if entry["@level"] == "debug" {
+			calls = append(calls, entry)
+		}
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected a debug log per call, got %v", calls)
+	}
+
+	// executeCloud9 path
+	if calls[0]["@message"] != "Cloud9 call" || calls[0]["target"] != OPERATION_PREFIX+".DescribeSSHRemote" ||
+		calls[0]["environment_id"] != env.EnvironmentId || calls[0]["retries"] != float64(1) ||
+		calls[0]["status_code"] != float64(http.StatusOK) || !strings.HasPrefix(calls[0]["request_id"].(string), "fake-request-") {
+		t.Errorf("unexpected log %v", calls[0])
+	}
+	if _, ok := calls[0]["latency_ms"]; !ok {
+		t.Errorf("expected the latency to be logged, got %v", calls[0])
+	}
+
+	// SDK path
+	if calls[1]["@message"] != "Cloud9 call failed" || calls[1]["target"] != OPERATION_PREFIX+".DescribeEnvironmentMemberships" ||
+		calls[1]["environment_id"] != "missing" || calls[1]["retries"] != float64(0) ||
+		calls[1]["status_code"] != float64(http.StatusBadRequest) || !strings.HasPrefix(calls[1]["request_id"].(string), "fake-request-") ||
+		!strings.Contains(calls[1]["error"].(string), "NotFoundException") {
+		t.Errorf("unexpected log %v", calls[1])
+	}
+}
+
+func TestLogAttemptsRedaction(t *testing.T) {
+	client, _ := newTestClient(t)
+	env := createTestEnvironment(t, client)
+	settings := `{"runners":{"password":"hunter2"}}`
+
+	for _, logBodies := range []bool{false, true} {
+		client.options.LogBodies = logBodies
+
+		var output bytes.Buffer
+		ctx := tflogtest.RootLogger(context.Background(), &output)
+
+		if err := client.UpdateEnvironmentSettings(ctx, env.EnvironmentId, settings); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.GetMemberShips(ctx, env.EnvironmentId); err != nil {
+			t.Fatal(err)
+		}
+
+		raw := output.String()
+		if strings.Contains(raw, "hunter2") || strings.Contains(raw, "Signature=") || strings.Contains(raw, "AKIDTEST") {
+			t.Errorf("expected the secrets to be redacted, got %s", raw)
+		}
+
+		attempts := decodeLogs(t, &output, "Cloud9 call attempt")
+		if len(attempts) != 2 {
+			t.Fatalf("expected a trace log per attempt, got %v", attempts)
+		}
+		for _, attempt := range attempts {
+			if attempt["@level"] != "trace" {
+				t.Errorf("expected the attempts to be logged at trace level, got %v", attempt["@level"])
+			}
+			headers := attempt["request_headers"].(map[string]interface{})
+			if headers["Authorization"] != REDACTED {
+				t.Errorf("expected the authorization header to be redacted, got %v", headers["Authorization"])
+			}
+			_, hasRequest := attempt["request_body"]
+			_, hasResponse := attempt["response_body"]
+			if hasRequest != logBodies || hasResponse != logBodies {
+				t.Errorf("expected the bodies to be logged: %v, got %v", logBodies, attempt)
+			}
+		}
+
+		if logBodies {
+			var body struct {
+				Settings string
+			}
+			if err := json.Unmarshal([]byte(attempts[0]["request_body"].(string)), &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Settings != `{"runners":{"password":"***"}}` {
+				t.Errorf("unexpected settings %s", body.Settings)
+			}
+			if !strings.Contains(attempts[1]["response_body"].(string), env.EnvironmentId) {
+				t.Errorf("expected the SDK response to be logged, got %v", attempts[1]["response_body"])
+			}
+		}
+	}
+}
+
+func TestSanitizeBody(t *testing.T) {
+	testCases := []struct {
+		body     string
+		expected string
+	}{
+		{`{"name":"env","token":"abc"}`, `{"name":"env","token":"***"}`},
+		{`{"items":[{"SecretAccessKey":"abc"},{"key":"value"}]}`, `{"items":[{"SecretAccessKey":"***"},{"key":"value"}]}`},
+		{`{"settings":"{\"private_key\":\"abc\"}"}`, `{"settings":"{\"private_key\":\"***\"}"}`},
+		{`not json`, `(8 bytes, not JSON)`},
+	}
+
+	for _, testCase := range testCases {
+		if res := sanitizeBody([]byte(testCase.body)); res != testCase.expected {
+			t.Errorf("sanitizeBody(%s) = %s, expected %s", testCase.body, res, testCase.expected)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package aws
 
+import "time"
+
 const (
 	OWNER      = "owner"
 	READ_WRITE = "read-write"
@@ -11,6 +13,8 @@ type Cloud9EnvironmentMembership struct {
 	Permissions   string `json:"permissions"`
 	UserARN       string `json:"userArn"`
 	UserID        string `json:"userId"`
+	// LastAccess is nil if the user never opened the environment.
+	LastAccess *time.Time `json:"lastAccess,omitempty"`
 }
 
 type Tag struct {
@@ -25,12 +29,25 @@ type Cloud9SSHEnvironment struct {
 	Description     string `json:"description,omitempty"`
 	LoginName       string `json:"loginName"`
 	Hostname        string `json:"host"`
-	Port            int16  `json:"port"`
+	Port            int    `json:"port"`
 	EnvironmentPath string `json:"environmentPath,omitempty"`
 	NodePath        string `json:"nodePath,omitempty"`
 	BastionHost     string `json:"bastionHost,omitempty"`
 	DryRun          bool   `json:"dryRun"`
 	Tags            []Tag  `json:"tags"`
+	Type            string `json:"-"`
+	OwnerArn        string `json:"-"`
+}
+
+type Cloud9EC2Environment struct {
+	Arn            string `json:"arn,omitempty"`
+	EnvironmentId  string `json:"environment_id"`
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	ConnectionType string `json:"connectionType"`
+	Type           string `json:"-"`
+	OwnerArn       string `json:"ownerArn"`
+	Tags           []Tag  `json:"tags"`
 }
 
 type CreateEnvironmentSSHRequest struct {
@@ -38,7 +55,7 @@ type CreateEnvironmentSSHRequest struct {
 	Description     string `json:"description,omitempty"`
 	LoginName       string `json:"loginName"`
 	Hostname        string `json:"host"`
-	Port            int16  `json:"port"`
+	Port            int    `json:"port"`
 	EnvironmentPath string `json:"environmentPath,omitempty"`
 	NodePath        string `json:"nodePath,omitempty"`
 	BastionHost     string `json:"bastionHost,omitempty"`
@@ -59,7 +76,7 @@ type SSHRemoteEnvironmentDescription struct {
 	Hostname        string `json:"host"`
 	Description     string `json:"description,omitempty"`
 	LoginName       string `json:"loginName"`
-	Port            int16  `json:"port"`
+	Port            int    `json:"port"`
 	NodePath        string `json:"nodePath"`
 	BastionHost     string `json:"bastionHost"`
 }
@@ -72,7 +89,7 @@ type UpdateSSHRemoteRequest struct {
 	EnvironmentId   string `json:"environmentId"`
 	LoginName       string `json:"loginName"`
 	Hostname        string `json:"host"`
-	Port            int16  `json:"port"`
+	Port            int    `json:"port"`
 	EnvironmentPath string `json:"environmentPath"`
 	NodePath        string `json:"nodePath"`
 	BastionHost     string `json:"bastionHost"`
@@ -86,3 +103,21 @@ type AWSError struct {
 	ExceptionType string `json:"__type"`
 	Message       string `json:"message"`
 }
+
+type GetEnvironmentSettingsRequest struct {
+	EnvironmentId string `json:"environmentId"`
+}
+
+// SettingsResult holds a settings document, JSON encoded.
+type SettingsResult struct {
+	Settings string `json:"settings"`
+}
+
+type UpdateUserSettingsRequest struct {
+	Settings string `json:"settings"`
+}
+
+type UpdateEnvironmentSettingsRequest struct {
+	EnvironmentId string `json:"environmentId"`
+	Settings      string `json:"settings"`
+}
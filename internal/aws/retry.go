@@ -0,0 +1,175 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	awsclient "github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+const (
+	DEFAULT_MAX_RETRIES      = 10
+	DEFAULT_MIN_RETRY_DELAY  = 100 * time.Millisecond
+	DEFAULT_MAX_RETRY_DELAY  = 20 * time.Second
+	DEFAULT_REQUEST_TIMEOUT  = time.Minute
+	MIN_THROTTLE_RETRY_DELAY = 500 * time.Millisecond
+	DEFAULT_WAITER_DELAY     = 5 * time.Second
+	DEFAULT_WAITER_TIMEOUT   = 20 * time.Minute
+	DEFAULT_CONCURRENCY      = 8
+	DEFAULT_REQUESTS_PER_SEC = 20
+)
+
+// ClientOptions configures the endpoint and the retry behaviour shared by
+// the executeCloud9 and the SDK paths of AWSCloud9Client.
+type ClientOptions struct {
+	// Endpoint overrides the cloud9 endpoint when not empty.
+	Endpoint string
+	// MaxRetries is the number of retries of throttled and 5xx responses,
+	// 0 disables the retries and a negative value uses the default.
+	MaxRetries     int
+	MinRetryDelay  time.Duration
+	MaxRetryDelay  time.Duration
+	RequestTimeout time.Duration
+	// WaiterDelay is the delay between two polls of the environment status
+	// and WaiterTimeout bounds the time spent waiting for a status.
+	WaiterDelay   time.Duration
+	WaiterTimeout time.Duration
+	// Concurrency is the number of environments described at once by the
+	// plural lookups, whose calls are bounded by RequestsPerSecond.
+	Concurrency       int
+	RequestsPerSecond int
+	// CacheReads enables the read-through cache of the client, see
+	// readCache.
+	CacheReads bool
+	// LogBodies adds the sanitized request and response bodies to the TRACE
+	// logs of the calls.
+	LogBodies bool
+	// Tags are the provider-level tag settings, the ignored tags are
+	// filtered out of the tags read by GetTags.
+	Tags TagsConfig
+}
+
+func (options *ClientOptions) setDefaults() {
+	if options.MaxRetries < 0 {
+		options.MaxRetries = DEFAULT_MAX_RETRIES
+	}
+	if options.MinRetryDelay <= 0 {
+		options.MinRetryDelay = DEFAULT_MIN_RETRY_DELAY
+	}
+	if options.MaxRetryDelay <= 0 {
+		options.MaxRetryDelay = DEFAULT_MAX_RETRY_DELAY
+	}
+	if options.MaxRetryDelay < options.MinRetryDelay {
+		options.MaxRetryDelay = options.MinRetryDelay
+	}
+	if options.RequestTimeout <= 0 {
+		options.RequestTimeout = DEFAULT_REQUEST_TIMEOUT
+	}
+	if options.WaiterDelay <= 0 {
+		options.WaiterDelay = DEFAULT_WAITER_DELAY
+	}
+	if options.WaiterTimeout <= 0 {
+		options.WaiterTimeout = DEFAULT_WAITER_TIMEOUT
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = DEFAULT_CONCURRENCY
+	}
+	if options.RequestsPerSecond <= 0 {
+		options.RequestsPerSecond = DEFAULT_REQUESTS_PER_SEC
+	}
+}
+
+// DefaultClientOptions returns the options used when nothing is configured.
+func DefaultClientOptions() ClientOptions {
+	options := ClientOptions{MaxRetries: -1}
+	options.setDefaults()
+	return options
+}
+
+// isRetryable reports whether a failed attempt of operation may succeed when
+// sent again. Client-side timeouts are only retried for the read-only
+// operations, a mutating call which timed out may have been applied.
+func isRetryable(operation string, err error) bool {
+	if IsThrottling(err) {
+		return true
+	}
+	if cloud9Err, ok := AsCloud9Error(err); ok {
+		return cloud9Err.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout() && !isMutating(operation)
+}
+
+// sdkRetryer applies the policy of isRetryable to the mutating calls of the
+// SDK client, the default retryer would send them again after a timeout or
+// a connection reset.
+type sdkRetryer struct {
+	awsclient.DefaultRetryer
+}
+
+func (r sdkRetryer) ShouldRetry(req *request.Request) bool {
+	if isMutating(req.Operation.Name) && !isRetryable(req.Operation.Name, req.Error) {
+		return false
+	}
+	return r.DefaultRetryer.ShouldRetry(req)
+}
+
+func (options *ClientOptions) minThrottleDelay() time.Duration {
+	if options.MaxRetryDelay < MIN_THROTTLE_RETRY_DELAY {
+		return options.MaxRetryDelay
+	}
+	return MIN_THROTTLE_RETRY_DELAY
+}
+
+// retryDelay computes an exponential backoff with jitter for the given
+// attempt, starting at 0. Throttled calls wait at least
+// MIN_THROTTLE_RETRY_DELAY and a Retry-After header is honored up to
+// MaxRetryDelay.
+func (options *ClientOptions) retryDelay(attempt int, err error, res *http.Response) time.Duration {
+	base := options.MinRetryDelay
+	if IsThrottling(err) && base < options.minThrottleDelay() {
+		base = options.minThrottleDelay()
+	}
+
+	delay := options.MaxRetryDelay
+	if attempt < 32 && base<<attempt > 0 && base<<attempt < options.MaxRetryDelay {
+		delay = base << attempt
+	}
+	// equal jitter: wait between half and the whole delay
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	if res != nil {
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter := time.Duration(seconds) * time.Second
+			if retryAfter > options.MaxRetryDelay {
+				retryAfter = options.MaxRetryDelay
+			}
+			if retryAfter > delay {
+				delay = retryAfter
+			}
+		}
+	}
+
+	return delay
+}
+
+// sleep waits for delay, returning early with the error of ctx if it is
+// cancelled.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
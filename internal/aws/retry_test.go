@@ -0,0 +1,188 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloud9"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func TestRetryThrottling(t *testing.T) {
+	client, server := newTestClient(t)
+
+	server.InjectError("GetUserPublicKey", &fakecloud9.Error{
+		Status:        http.StatusBadRequest,
+		ExceptionType: "ThrottlingException",
+	}, 2)
+	server.InjectError("DescribeEnvironmentMemberships", &fakecloud9.Error{
+		Status:        http.StatusTooManyRequests,
+		ExceptionType: "TooManyRequestsException",
+	}, 2)
+
+	if _, err := client.GetUserPublicKey(context.Background()); err != nil {
+		t.Errorf("GetUserPublicKey: %s", err)
+	}
+	if calls := server.Calls("GetUserPublicKey"); calls != 3 {
+		t.Errorf("expected 3 GetUserPublicKey calls, got %d", calls)
+	}
+
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+	if _, err := client.GetMemberShips(context.Background(), envId); err != nil {
+		t.Errorf("GetMemberShips: %s", err)
+	}
+	if calls := server.Calls("DescribeEnvironmentMemberships"); calls != 3 {
+		t.Errorf("expected 3 DescribeEnvironmentMemberships calls, got %d", calls)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	client, server := newTestClient(t)
+
+	server.InjectError("GetUserPublicKey", &fakecloud9.Error{
+		Status:        http.StatusServiceUnavailable,
+		ExceptionType: "ServiceUnavailableException",
+	}, 10)
+
+	if _, err := client.GetUserPublicKey(context.Background()); err == nil {
+		t.Errorf("expected an error once the retries are exhausted")
+	}
+	if calls := server.Calls("GetUserPublicKey"); calls != 4 {
+		t.Errorf("expected 4 GetUserPublicKey calls, got %d", calls)
+	}
+}
+
+func TestRetryClientErrors(t *testing.T) {
+	client, server := newTestClient(t)
+
+	if _, err := client.DescribeSSHRemote(context.Background(), "missing"); !IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if calls := server.Calls("DescribeSSHRemote"); calls != 1 {
+		t.Errorf("client errors must not be retried, got %d calls", calls)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	options := ClientOptions{
+		MaxRetries:    5,
+		MinRetryDelay: 100 * time.Millisecond,
+		MaxRetryDelay: time.Second,
+	}
+	serverErr := &Cloud9Error{ExceptionType: "InternalFailure", StatusCode: http.StatusInternalServerError}
+	throttleErr := &Cloud9Error{ExceptionType: "ThrottlingException", StatusCode: http.StatusBadRequest}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		delay := options.retryDelay(attempt, serverErr, nil)
+		if delay > options.MaxRetryDelay {
+			t.Errorf("attempt %d: delay %s exceeds the maximum", attempt, delay)
+		}
+		expected := options.MinRetryDelay << attempt
+		if expected > options.MaxRetryDelay {
+			expected = options.MaxRetryDelay
+		}
+		if delay < expected/2 {
+			t.Errorf("attempt %d: delay %s is below %s", attempt, delay, expected/2)
+		}
+	}
+
+	if delay := options.retryDelay(0, throttleErr, nil); delay < MIN_THROTTLE_RETRY_DELAY/2 {
+		t.Errorf("throttled delay %s is below %s", delay, MIN_THROTTLE_RETRY_DELAY/2)
+	}
+
+	res := &http.Response{Header: http.Header{"Retry-After": []string{"10"}}}
+	if delay := options.retryDelay(0, throttleErr, res); delay != options.MaxRetryDelay {
+		t.Errorf("expected Retry-After to be capped to %s, got %s", options.MaxRetryDelay, delay)
+	}
+
+	if isRetryable("DescribeSSHRemote", errors.New("boom")) {
+		t.Errorf("untyped errors must not be retried")
+	}
+}
+
+// timeoutError is a client-side timeout, like the ones of http.Client.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryTimeouts(t *testing.T) {
+	throttleErr := &Cloud9Error{ExceptionType: "ThrottlingException", StatusCode: http.StatusBadRequest}
+	serverErr := &Cloud9Error{ExceptionType: "InternalFailure", StatusCode: http.StatusInternalServerError}
+
+	if !isRetryable("DescribeSSHRemote", timeoutError{}) {
+		t.Errorf("timeouts of read-only operations must be retried")
+	}
+	// a create which timed out may have been applied by the server
+	for _, operation := range []string{"CreateEnvironmentSSH", "CreateEnvironmentMembership"} {
+		if isRetryable(operation, timeoutError{}) {
+			t.Errorf("timeouts of %s must not be retried", operation)
+		}
+		if !isRetryable(operation, throttleErr) || !isRetryable(operation, serverErr) {
+			t.Errorf("throttled and 5xx %s calls must be retried", operation)
+		}
+	}
+}
+
+func TestRetrySDKTimeouts(t *testing.T) {
+	client, server := newTestClient(t)
+	client.client.Timeout = 50 * time.Millisecond
+
+	// the environment is created but the response comes too late, sending
+	// the call again would create a second environment
+	server.InjectDelay("CreateEnvironmentEC2", time.Second, 1)
+	_, err := client.Cloud9.CreateEnvironmentEC2WithContext(context.Background(), &cloud9.CreateEnvironmentEC2Input{
+		Name:         aws.String("env"),
+		InstanceType: aws.String("t3.small"),
+	})
+	if err == nil {
+		t.Fatalf("expected the call to time out")
+	}
+	if calls := server.Calls("CreateEnvironmentEC2"); calls != 1 {
+		t.Errorf("timed out mutating calls must not be retried, got %d calls", calls)
+	}
+
+	server.InjectDelay("ListEnvironments", time.Second, 1)
+	if _, err := client.ListEnvironments(context.Background()); err != nil {
+		t.Errorf("ListEnvironments: %s", err)
+	}
+	if calls := server.Calls("ListEnvironments"); calls != 2 {
+		t.Errorf("expected the timed out ListEnvironments call to be retried once, got %d calls", calls)
+	}
+}
+
+func TestRetryCancelled(t *testing.T) {
+	client, server := newTestClient(t)
+	client.options.MinRetryDelay = time.Minute
+	client.options.MaxRetryDelay = time.Minute
+
+	server.InjectError("GetUserPublicKey", &fakecloud9.Error{
+		Status:        http.StatusServiceUnavailable,
+		ExceptionType: "ServiceUnavailableException",
+	}, 10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := client.GetUserPublicKey(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline exceeded error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("the retry delay was not interrupted, took %s", elapsed)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.GetMemberShips(ctx, "env"); err == nil {
+		t.Errorf("expected an error for a cancelled context")
+	}
+	if calls := server.Calls("DescribeEnvironmentMemberships"); calls != 0 {
+		t.Errorf("a cancelled context must not reach the server, got %d calls", calls)
+	}
+}
@@ -0,0 +1,87 @@
+package aws
+
+import "strings"
+
+// AWS_TAG_PREFIX prefixes the tags reserved by AWS, which can not be
+// modified and are always ignored.
+const AWS_TAG_PREFIX = "aws:"
+
+// IgnoreTagsConfig lists the tags which are never read nor modified.
+type IgnoreTagsConfig struct {
+	Keys        []string
+	KeyPrefixes []string
+}
+
+// TagsConfig holds the provider-level tag settings shared by every taggable
+// resource.
+type TagsConfig struct {
+	// DefaultTags are added to the tags of every resource, the resource tags
+	// take precedence on conflicting keys.
+	DefaultTags map[string]string
+	IgnoreTags  IgnoreTagsConfig
+}
+
+// IsIgnored reports whether a tag key is ignored.
+func (config *TagsConfig) IsIgnored(key string) bool {
+	if strings.HasPrefix(key, AWS_TAG_PREFIX) {
+		return true
+	}
+	for _, ignored := range config.IgnoreTags.Keys {
+		if key == ignored {
+			return true
+		}
+	}
+	for _, prefix := range config.IgnoreTags.KeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveIgnored returns the tags which are not ignored.
+func (config *TagsConfig) RemoveIgnored(tags []Tag) []Tag {
+	res := make([]Tag, 0, len(tags))
+	for _, tag := range tags {
+		if !config.IsIgnored(tag.Key) {
+			res = append(res, tag)
+		}
+	}
+	return res
+}
+
+// MergeDefaults returns the tags to apply to a resource: the default tags
+// overridden by tags, without the ignored keys.
+func (config *TagsConfig) MergeDefaults(tags map[string]string) map[string]string {
+	res := make(map[string]string, len(config.DefaultTags)+len(tags))
+	for key, value := range config.DefaultTags {
+		if !config.IsIgnored(key) {
+			res[key] = value
+		}
+	}
+	for key, value := range tags {
+		if !config.IsIgnored(key) {
+			res[key] = value
+		}
+	}
+	return res
+}
+
+// ResourceTags returns the tags of tagsAll which belong to the resource
+// configuration: the default tags are left out, unless their value was
+// changed or they are part of configured.
+func (config *TagsConfig) ResourceTags(tagsAll map[string]string, configured map[string]string) map[string]string {
+	res := make(map[string]string, len(tagsAll))
+	for key, value := range tagsAll {
+		if config.IsIgnored(key) {
+			continue
+		}
+		if defaultValue, ok := config.DefaultTags[key]; ok && defaultValue == value {
+			if _, ok := configured[key]; !ok {
+				continue
+			}
+		}
+		res[key] = value
+	}
+	return res
+}
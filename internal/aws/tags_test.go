@@ -0,0 +1,87 @@
+package aws
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestTagsConfigIgnored(t *testing.T) {
+	config := TagsConfig{
+		IgnoreTags: IgnoreTagsConfig{
+			Keys:        []string{"owner"},
+			KeyPrefixes: []string{"kubernetes.io/"},
+		},
+	}
+
+	keys := map[string]bool{
+		"owner":                   true,
+		"owners":                  false,
+		"kubernetes.io/cluster/a": true,
+		"kubernetes.io":           false,
+		"aws:cloud9:owner":        true,
+		"team":                    false,
+	}
+	for key, ignored := range keys {
+		if config.IsIgnored(key) != ignored {
+			t.Errorf("%q: expected ignored=%t", key, ignored)
+		}
+	}
+
+	tags := config.RemoveIgnored([]Tag{{"owner", "me"}, {"team", "infra"}, {"aws:cloud9:owner", "me"}})
+	if !reflect.DeepEqual(tags, []Tag{{"team", "infra"}}) {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
+
+func TestTagsConfigMergeDefaults(t *testing.T) {
+	config := TagsConfig{
+		DefaultTags: map[string]string{"stage": "dev", "team": "infra", "owner": "me"},
+		IgnoreTags:  IgnoreTagsConfig{Keys: []string{"owner"}},
+	}
+
+	merged := config.MergeDefaults(map[string]string{"stage": "prod", "name": "env"})
+	expected := map[string]string{"stage": "prod", "team": "infra", "name": "env"}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("expected %v, got %v", expected, merged)
+	}
+}
+
+func TestTagsConfigResourceTags(t *testing.T) {
+	config := TagsConfig{
+		DefaultTags: map[string]string{"stage": "dev", "team": "infra"},
+	}
+
+	tagsAll := map[string]string{"stage": "dev", "team": "ops", "name": "env", "aws:cloud9:owner": "me"}
+
+	// team was changed out-of-band and name was added to the configuration
+	tags := config.ResourceTags(tagsAll, map[string]string{"name": "env"})
+	expected := map[string]string{"team": "ops", "name": "env"}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("expected %v, got %v", expected, tags)
+	}
+
+	// a default tag also set on the resource is kept
+	tags = config.ResourceTags(tagsAll, map[string]string{"stage": "dev"})
+	expected = map[string]string{"stage": "dev", "team": "ops", "name": "env"}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("expected %v, got %v", expected, tags)
+	}
+}
+
+func TestGetTagsIgnored(t *testing.T) {
+	client, server := newTestClient(t)
+	client.options.Tags.IgnoreTags.KeyPrefixes = []string{"managed-by/"}
+
+	envId := server.AddEC2Environment("env", map[string]string{"team": "infra", "managed-by/tool": "x"})
+	environments, err := client.GetEC2Environments(context.Background(), envId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(environments[0].Tags, []Tag{{"team", "infra"}}) {
+		t.Errorf("expected the ignored tags to be filtered, got %v", environments[0].Tags)
+	}
+	if tags := server.Tags(envId); len(tags) != 2 {
+		t.Errorf("expected the ignored tags to be kept on the environment, got %v", tags)
+	}
+}
@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloud9"
+)
+
+// EnvironmentStatusError is returned by the waiters when an environment
+// lands in the error status.
+type EnvironmentStatusError struct {
+	EnvironmentId string
+	Message       string
+}
+
+func (e *EnvironmentStatusError) Error() string {
+	if len(e.Message) == 0 {
+		return fmt.Sprintf("environment %s is in the error status", e.EnvironmentId)
+	}
+	return fmt.Sprintf("environment %s is in the error status: %s", e.EnvironmentId, e.Message)
+}
+
+func (client *AWSCloud9Client) DescribeEnvironmentStatus(ctx context.Context, environmentId string) (*cloud9.DescribeEnvironmentStatusOutput, error) {
+	output, err := client.Cloud9.DescribeEnvironmentStatusWithContext(ctx, &cloud9.DescribeEnvironmentStatusInput{
+		EnvironmentId: &environmentId,
+	})
+	return output, wrapError(err)
+}
+
+// waitEnvironment polls the status of the environment until done returns
+// true, an error status is reached or WaiterTimeout elapses.
+func (client *AWSCloud9Client) waitEnvironment(ctx context.Context, environmentId string, done func(status string) bool) error {
+	ctx, cancel := context.WithTimeout(ctx, client.options.WaiterTimeout)
+	defer cancel()
+
+	status := "unknown"
+	for {
+		output, err := client.DescribeEnvironmentStatus(ctx, environmentId)
+		if err != nil && ctx.Err() != nil {
+			return waitError(environmentId, status, ctx.Err())
+		} else if err != nil {
+			return err
+		}
+
+		status = aws.StringValue(output.Status)
+		if status == cloud9.EnvironmentStatusError {
+			return &EnvironmentStatusError{
+				EnvironmentId: environmentId,
+				Message:       aws.StringValue(output.Message),
+			}
+		} else if done(status) {
+			return nil
+		}
+
+		if err = sleep(ctx, client.options.WaiterDelay); err != nil {
+			return waitError(environmentId, status, err)
+		}
+	}
+}
+
+// waitError reports the context error which stopped a waiter, the timeout
+// is only mentioned when the deadline was exceeded.
+func waitError(environmentId string, status string, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("cancelled while waiting for environment %s, last status %s: %w", environmentId, status, err)
+	}
+	return fmt.Errorf("timed out waiting for environment %s, last status %s: %w", environmentId, status, err)
+}
+
+// WaitEnvironmentReady waits for a newly created environment to leave the
+// creating and connecting statuses.
+func (client *AWSCloud9Client) WaitEnvironmentReady(ctx context.Context, environmentId string) error {
+	return client.waitEnvironment(ctx, environmentId, func(status string) bool {
+		return status == cloud9.EnvironmentStatusReady || status == cloud9.EnvironmentStatusStopped
+	})
+}
+
+// WaitEnvironmentDeleted waits for DescribeEnvironmentStatus to stop finding
+// the environment.
+func (client *AWSCloud9Client) WaitEnvironmentDeleted(ctx context.Context, environmentId string) error {
+	err := client.waitEnvironment(ctx, environmentId, func(status string) bool {
+		return false
+	})
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
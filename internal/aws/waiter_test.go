@@ -0,0 +1,96 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/cloud9"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func TestWaitEnvironmentReady(t *testing.T) {
+	client, server := newTestClient(t)
+	server.SetTransitionPolls(3, 0)
+
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+	if err := client.WaitEnvironmentReady(context.Background(), envId); err != nil {
+		t.Fatalf("WaitEnvironmentReady: %s", err)
+	}
+	if calls := server.Calls("DescribeEnvironmentStatus"); calls != 4 {
+		t.Errorf("expected 4 DescribeEnvironmentStatus calls, got %d", calls)
+	}
+	if env := server.Environment(envId); env.Status != "ready" {
+		t.Errorf("unexpected status %s", env.Status)
+	}
+}
+
+func TestWaitEnvironmentError(t *testing.T) {
+	client, server := newTestClient(t)
+
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+	server.SetStatus(envId, "error", "could not connect to the host")
+
+	err := client.WaitEnvironmentReady(context.Background(), envId)
+	var statusErr *EnvironmentStatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected an EnvironmentStatusError, got %v", err)
+	}
+	if statusErr.EnvironmentId != envId || !strings.Contains(err.Error(), "could not connect to the host") {
+		t.Errorf("unexpected error %s", err)
+	}
+}
+
+func TestWaitEnvironmentTimeout(t *testing.T) {
+	client, server := newTestClient(t)
+	client.options.WaiterTimeout = 20 * time.Millisecond
+
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+	server.SetStatus(envId, "connecting", "")
+
+	err := client.WaitEnvironmentReady(context.Background(), envId)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a deadline exceeded error, got %v", err)
+	}
+}
+
+func TestWaitEnvironmentCancelled(t *testing.T) {
+	client, server := newTestClient(t)
+	client.options.WaiterDelay = time.Second
+
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+	server.SetStatus(envId, "connecting", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	err := client.WaitEnvironmentReady(ctx, envId)
+	if !errors.Is(err, context.Canceled) || strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a cancellation error, got %v", err)
+	}
+}
+
+func TestWaitEnvironmentDeleted(t *testing.T) {
+	client, server := newTestClient(t)
+	server.SetTransitionPolls(0, 2)
+
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+	if _, err := client.Cloud9.DeleteEnvironment(&cloud9.DeleteEnvironmentInput{EnvironmentId: &envId}); err != nil {
+		t.Fatalf("DeleteEnvironment: %s", err)
+	}
+	if server.Environment(envId) == nil {
+		t.Fatalf("the environment should still be deleting")
+	}
+
+	if err := client.WaitEnvironmentDeleted(context.Background(), envId); err != nil {
+		t.Fatalf("WaitEnvironmentDeleted: %s", err)
+	}
+	if server.Environment(envId) != nil {
+		t.Errorf("the environment should be deleted")
+	}
+	if calls := server.Calls("DescribeEnvironmentStatus"); calls != 3 {
+		t.Errorf("expected 3 DescribeEnvironmentStatus calls, got %d", calls)
+	}
+}
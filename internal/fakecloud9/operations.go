@@ -0,0 +1,626 @@
+package fakecloud9
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+var operations = map[string]operation{
+	"CreateEnvironmentSSH":           createEnvironmentSSH,
+	"CreateEnvironmentEC2":           createEnvironmentEC2,
+	"DescribeSSHRemote":              describeSSHRemote,
+	"UpdateSSHRemote":                updateSSHRemote,
+	"DescribeEnvironments":           describeEnvironments,
+	"DescribeEnvironmentStatus":      describeEnvironmentStatus,
+	"ListEnvironments":               listEnvironments,
+	"UpdateEnvironment":              updateEnvironment,
+	"DeleteEnvironment":              deleteEnvironment,
+	"DescribeEnvironmentMemberships": describeEnvironmentMemberships,
+	"CreateEnvironmentMembership":    createEnvironmentMembership,
+	"UpdateEnvironmentMembership":    updateEnvironmentMembership,
+	"DeleteEnvironmentMembership":    deleteEnvironmentMembership,
+	"ListTagsForResource":            listTagsForResource,
+	"TagResource":                    tagResource,
+	"UntagResource":                  untagResource,
+	"GetUserPublicKey":               getUserPublicKey,
+	"GetUserSettings":                getUserSettings,
+	"UpdateUserSettings":             updateUserSettings,
+	"GetEnvironmentSettings":         getEnvironmentSettings,
+	"UpdateEnvironmentSettings":      updateEnvironmentSettings,
+}
+
+func decode(body []byte, input interface{}) *Error {
+	if err := json.Unmarshal(body, input); err != nil {
+		return badRequest("could not decode request: %s", err)
+	}
+	return nil
+}
+
+type environmentDescription struct {
+	Arn            string               `json:"arn"`
+	Id             string               `json:"id"`
+	Name           string               `json:"name"`
+	Description    string               `json:"description,omitempty"`
+	Type           string               `json:"type"`
+	ConnectionType string               `json:"connectionType,omitempty"`
+	OwnerArn       string               `json:"ownerArn"`
+	Lifecycle      environmentLifecycle `json:"lifecycle"`
+}
+
+type environmentLifecycle struct {
+	Status string `json:"status"`
+}
+
+type membershipDescription struct {
+	EnvironmentId string   `json:"environmentId"`
+	Permissions   string   `json:"permissions"`
+	UserArn       string   `json:"userArn"`
+	UserId        string   `json:"userId"`
+	LastAccess    *float64 `json:"lastAccess,omitempty"`
+}
+
+func describeMembership(membership *Membership) membershipDescription {
+	res := membershipDescription{
+		EnvironmentId: membership.EnvironmentId,
+		Permissions:   membership.Permissions,
+		UserArn:       membership.UserArn,
+		UserId:        membership.UserId,
+	}
+	if membership.LastAccess != nil {
+		lastAccess := float64(membership.LastAccess.Unix())
+		res.LastAccess = &lastAccess
+	}
+	return res
+}
+
+func (s *Server) environment(id string) (*Environment, *Error) {
+	env, ok := s.environments[id]
+	if !ok {
+		return nil, notFound("The environment %s does not exist", id)
+	}
+	return env, nil
+}
+
+func (s *Server) sshEnvironment(id string) (*Environment, *Error) {
+	env, err := s.environment(id)
+	if err != nil {
+		return nil, err
+	}
+	if env.Remote == nil {
+		return nil, badRequest("The environment %s is not an SSH environment", id)
+	}
+	return env, nil
+}
+
+func createEnvironmentSSH(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		SSHRemote
+		DryRun bool  `json:"dryRun"`
+		Tags   []Tag `json:"tags"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	if len(input.Name) == 0 || len(input.LoginName) == 0 || len(input.Hostname) == 0 {
+		return nil, badRequest("name, loginName and host are required")
+	}
+	if input.Port == 0 {
+		input.Port = 22
+	}
+	if len(input.EnvironmentPath) == 0 {
+		input.EnvironmentPath = DEFAULT_ENVIRONMENT_PATH
+	}
+	if len(input.NodePath) == 0 {
+		input.NodePath = DEFAULT_NODE_PATH
+	}
+
+	env := &Environment{
+		Name:        input.Name,
+		Description: input.Description,
+		Type:        "ssh",
+		Remote:      &input.SSHRemote,
+	}
+	s.addEnvironment(env, input.Tags)
+
+	return map[string]string{"environmentId": env.Id}, nil
+}
+
+func createEnvironmentEC2(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		Name                     string `json:"name"`
+		Description              string `json:"description"`
+		InstanceType             string `json:"instanceType"`
+		ImageId                  string `json:"imageId"`
+		SubnetId                 string `json:"subnetId"`
+		ConnectionType           string `json:"connectionType"`
+		AutomaticStopTimeMinutes int64  `json:"automaticStopTimeMinutes"`
+		OwnerArn                 string `json:"ownerArn"`
+		Tags                     []Tag  `json:"tags"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	if len(input.Name) == 0 || len(input.InstanceType) == 0 {
+		return nil, badRequest("name and instanceType are required")
+	}
+	if len(input.ConnectionType) == 0 {
+		input.ConnectionType = "CONNECT_SSH"
+	}
+
+	env := &Environment{
+		Name:                     input.Name,
+		Description:              input.Description,
+		Type:                     "ec2",
+		ConnectionType:           input.ConnectionType,
+		OwnerArn:                 input.OwnerArn,
+		InstanceType:             input.InstanceType,
+		ImageId:                  input.ImageId,
+		SubnetId:                 input.SubnetId,
+		AutomaticStopTimeMinutes: input.AutomaticStopTimeMinutes,
+	}
+	s.addEnvironment(env, input.Tags)
+
+	return map[string]string{"environmentId": env.Id}, nil
+}
+
+func describeSSHRemote(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		EnvironmentId string `json:"environmentId"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	env, err := s.sshEnvironment(input.EnvironmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"remote": env.Remote}, nil
+}
+
+func updateSSHRemote(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		EnvironmentId string `json:"environmentId"`
+		SSHRemote
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	env, err := s.sshEnvironment(input.EnvironmentId)
+	if err != nil {
+		return nil, err
+	}
+	if input.Port == 0 {
+		input.Port = env.Remote.Port
+	}
+	if len(input.EnvironmentPath) == 0 {
+		input.EnvironmentPath = env.Remote.EnvironmentPath
+	}
+	if len(input.NodePath) == 0 {
+		input.NodePath = env.Remote.NodePath
+	}
+	remote := input.SSHRemote
+	env.Remote = &remote
+
+	return struct{}{}, nil
+}
+
+func describeEnvironments(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		EnvironmentIds []string `json:"environmentIds"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if len(input.EnvironmentIds) == 0 || len(input.EnvironmentIds) > DEFAULT_PAGE_SIZE {
+		return nil, badRequest("between 1 and %d environment ids must be provided", DEFAULT_PAGE_SIZE)
+	}
+
+	res := make([]environmentDescription, 0, len(input.EnvironmentIds))
+	for _, id := range input.EnvironmentIds {
+		env, ok := s.environments[id]
+		if !ok {
+			continue
+		}
+		res = append(res, environmentDescription{
+			Arn:            env.Arn,
+			Id:             env.Id,
+			Name:           env.Name,
+			Description:    env.Description,
+			Type:           env.Type,
+			ConnectionType: env.ConnectionType,
+			OwnerArn:       env.OwnerArn,
+			Lifecycle:      environmentLifecycle{Status: "CREATED"},
+		})
+	}
+
+	return map[string]interface{}{"environments": res}, nil
+}
+
+func describeEnvironmentStatus(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		EnvironmentId string `json:"environmentId"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	env, err := s.environment(input.EnvironmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	status := env.Status
+	if env.pendingPolls > 0 {
+		env.pendingPolls--
+		if env.pendingPolls == 0 && env.Status == "creating" {
+			env.Status = "ready"
+		} else if env.pendingPolls == 0 && env.Status == "deleting" {
+			s.removeEnvironment(env.Id)
+		}
+	}
+
+	return map[string]string{"status": status, "message": env.StatusMessage}, nil
+}
+
+// page returns the slice of items starting at nextToken and the token of the
+// following page.
+func page(length int, nextToken string, maxResults int) (int, int, *string, *Error) {
+	start := 0
+	if len(nextToken) > 0 {
+		var err error
+		if start, err = strconv.Atoi(nextToken); err != nil || start < 0 || start > length {
+			return 0, 0, nil, badRequest("invalid nextToken %s", nextToken)
+		}
+	}
+	if maxResults <= 0 {
+		maxResults = DEFAULT_PAGE_SIZE
+	}
+
+	end := start + maxResults
+	if end >= length {
+		return start, length, nil, nil
+	}
+	token := strconv.Itoa(end)
+	return start, end, &token, nil
+}
+
+func listEnvironments(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		NextToken  string `json:"nextToken"`
+		MaxResults int    `json:"maxResults"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	start, end, token, err := page(len(s.order), input.NextToken, input.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"environmentIds": append([]string{}, s.order[start:end]...),
+		"nextToken":      token,
+	}, nil
+}
+
+func updateEnvironment(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		EnvironmentId string  `json:"environmentId"`
+		Name          *string `json:"name"`
+		Description   *string `json:"description"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	env, err := s.environment(input.EnvironmentId)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		env.Name = *input.Name
+	}
+	if input.Description != nil {
+		env.Description = *input.Description
+	}
+
+	return struct{}{}, nil
+}
+
+func deleteEnvironment(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		EnvironmentId string `json:"environmentId"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	env, err := s.environment(input.EnvironmentId)
+	if err != nil {
+		return nil, err
+	}
+	if s.deletePolls > 0 {
+		env.Status = "deleting"
+		env.pendingPolls = s.deletePolls
+	} else {
+		s.removeEnvironment(input.EnvironmentId)
+	}
+
+	return struct{}{}, nil
+}
+
+func describeEnvironmentMemberships(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		EnvironmentId string   `json:"environmentId"`
+		UserArn       string   `json:"userArn"`
+		Permissions   []string `json:"permissions"`
+		NextToken     string   `json:"nextToken"`
+		MaxResults    int      `json:"maxResults"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	var candidates []*Membership
+	if len(input.EnvironmentId) > 0 {
+		if _, err := s.environment(input.EnvironmentId); err != nil {
+			return nil, err
+		}
+		candidates = s.memberships[input.EnvironmentId]
+	} else {
+		for _, id := range s.order {
+			candidates = append(candidates, s.memberships[id]...)
+		}
+	}
+
+	matches := make([]membershipDescription, 0, len(candidates))
+	for _, membership := range candidates {
+		if len(input.UserArn) > 0 && membership.UserArn != input.UserArn {
+			continue
+		}
+		if len(input.Permissions) > 0 && !contains(input.Permissions, membership.Permissions) {
+			continue
+		}
+		matches = append(matches, describeMembership(membership))
+	}
+
+	start, end, token, err := page(len(matches), input.NextToken, input.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"memberships": matches[start:end],
+		"nextToken":   token,
+	}, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+type membershipInput struct {
+	EnvironmentId string `json:"environmentId"`
+	UserArn       string `json:"userArn"`
+	Permissions   string `json:"permissions"`
+}
+
+func validPermissions(permissions string) *Error {
+	if permissions != "read-write" && permissions != "read-only" {
+		return badRequest("invalid permissions %s", permissions)
+	}
+	return nil
+}
+
+func createEnvironmentMembership(s *Server, body []byte) (interface{}, *Error) {
+	var input membershipInput
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.environment(input.EnvironmentId); err != nil {
+		return nil, err
+	}
+	if err := validPermissions(input.Permissions); err != nil {
+		return nil, err
+	}
+	if s.findMembership(input.EnvironmentId, input.UserArn) != nil {
+		return nil, conflict("The user %s is already a member of environment %s", input.UserArn, input.EnvironmentId)
+	}
+
+	membership := &Membership{
+		EnvironmentId: input.EnvironmentId,
+		Permissions:   input.Permissions,
+		UserArn:       input.UserArn,
+		UserId:        userId(input.UserArn),
+	}
+	s.memberships[input.EnvironmentId] = append(s.memberships[input.EnvironmentId], membership)
+
+	return map[string]interface{}{"membership": describeMembership(membership)}, nil
+}
+
+func updateEnvironmentMembership(s *Server, body []byte) (interface{}, *Error) {
+	var input membershipInput
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.environment(input.EnvironmentId); err != nil {
+		return nil, err
+	}
+	if err := validPermissions(input.Permissions); err != nil {
+		return nil, err
+	}
+	membership := s.findMembership(input.EnvironmentId, input.UserArn)
+	if membership == nil || membership.Permissions == "owner" {
+		return nil, notFound("The user %s is not a member of environment %s", input.UserArn, input.EnvironmentId)
+	}
+	membership.Permissions = input.Permissions
+
+	return map[string]interface{}{"membership": describeMembership(membership)}, nil
+}
+
+func deleteEnvironmentMembership(s *Server, body []byte) (interface{}, *Error) {
+	var input membershipInput
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.environment(input.EnvironmentId); err != nil {
+		return nil, err
+	}
+	memberships := s.memberships[input.EnvironmentId]
+	for i, membership := range memberships {
+		if membership.UserArn != input.UserArn {
+			continue
+		}
+		if membership.Permissions == "owner" {
+			return nil, badRequest("The owner of environment %s cannot be removed", input.EnvironmentId)
+		}
+		s.memberships[input.EnvironmentId] = append(memberships[:i:i], memberships[i+1:]...)
+		return struct{}{}, nil
+	}
+
+	return nil, notFound("The user %s is not a member of environment %s", input.UserArn, input.EnvironmentId)
+}
+
+func (s *Server) taggedResource(arn string) *Error {
+	if _, ok := s.tags[arn]; !ok {
+		return notFound("The resource %s does not exist", arn)
+	}
+	return nil
+}
+
+func listTagsForResource(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		ResourceARN string `json:"ResourceARN"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if err := s.taggedResource(input.ResourceARN); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"Tags": s.tags[input.ResourceARN]}, nil
+}
+
+func tagResource(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		ResourceARN string `json:"ResourceARN"`
+		Tags        []Tag  `json:"Tags"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if err := s.taggedResource(input.ResourceARN); err != nil {
+		return nil, err
+	}
+
+	s.tags[input.ResourceARN] = mergeTags(s.tags[input.ResourceARN], input.Tags)
+	return struct{}{}, nil
+}
+
+func untagResource(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		ResourceARN string   `json:"ResourceARN"`
+		TagKeys     []string `json:"TagKeys"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if err := s.taggedResource(input.ResourceARN); err != nil {
+		return nil, err
+	}
+
+	tags := make([]Tag, 0, len(s.tags[input.ResourceARN]))
+	for _, tag := range s.tags[input.ResourceARN] {
+		if !contains(input.TagKeys, tag.Key) {
+			tags = append(tags, tag)
+		}
+	}
+	s.tags[input.ResourceARN] = tags
+	return struct{}{}, nil
+}
+
+func getUserPublicKey(s *Server, body []byte) (interface{}, *Error) {
+	return map[string]string{"publicKey": s.PublicKey}, nil
+}
+
+// validSettings only accepts JSON objects, like the IDE does.
+func validSettings(settings string) *Error {
+	var document map[string]interface{}
+	if err := json.Unmarshal([]byte(settings), &document); err != nil || document == nil {
+		return badRequest("settings must be a JSON object")
+	}
+	return nil
+}
+
+func getUserSettings(s *Server, body []byte) (interface{}, *Error) {
+	return map[string]string{"settings": s.userSettingsOf(s.CallerArn)}, nil
+}
+
+func updateUserSettings(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		Settings string `json:"settings"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+	if err := validSettings(input.Settings); err != nil {
+		return nil, err
+	}
+
+	s.userSettings[s.CallerArn] = input.Settings
+	return struct{}{}, nil
+}
+
+func getEnvironmentSettings(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		EnvironmentId string `json:"environmentId"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	env, err := s.environment(input.EnvironmentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"settings": env.Settings}, nil
+}
+
+func updateEnvironmentSettings(s *Server, body []byte) (interface{}, *Error) {
+	var input struct {
+		EnvironmentId string `json:"environmentId"`
+		Settings      string `json:"settings"`
+	}
+	if err := decode(body, &input); err != nil {
+		return nil, err
+	}
+
+	env, err := s.environment(input.EnvironmentId)
+	if err != nil {
+		return nil, err
+	}
+	if err := validSettings(input.Settings); err != nil {
+		return nil, err
+	}
+
+	env.Settings = input.Settings
+	return struct{}{}, nil
+}
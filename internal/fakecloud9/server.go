@@ -0,0 +1,539 @@
+// Package fakecloud9 provides an in-process stand-in for the Cloud9 API,
+// serving the JSON 1.1 AWSCloud9WorkspaceManagementService targets used by
+// the provider so the client and the acceptance tests can run offline.
+package fakecloud9
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	OPERATION_PREFIX = "AWSCloud9WorkspaceManagementService."
+	AWS_JSON         = "application/x-amz-json-1.1"
+
+	DEFAULT_REGION           = "eu-west-3"
+	DEFAULT_ACCOUNT_ID       = "123456789012"
+	DEFAULT_CALLER_ARN       = "arn:aws:iam::123456789012:user/terraform"
+	DEFAULT_ENVIRONMENT_PATH = "~/"
+	DEFAULT_NODE_PATH        = "/usr/bin/node"
+	DEFAULT_PAGE_SIZE        = 25
+
+	// DEFAULT_SETTINGS is the settings document of the environments and the
+	// users which never updated it.
+	DEFAULT_SETTINGS = "{}"
+
+	// DEFAULT_PUBLIC_KEY is the key returned by GetUserPublicKey.
+	DEFAULT_PUBLIC_KEY = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQDdpya95Cll8ioJ0lLB9exIDiLhm7DOM5B/ajkMrMjnzyud7f30igS4/hqebWsxBC824LYR865q5tQDxPSoG+/09GjbpP2lH3haK5zd2XkryAku4hNlYUfXTLXwfRekm1jB9cX8G2n+iZ7ouSCxzPRy8GOimRAgTJ9YeYjWsIX/o84falGwWZaYWCj2y/+N6x++IWOS+y5sAF1l1BTAr2E5cd2UxFik92yLvuKEPpJnVyrW8a63I/HkLZn2lYlQ++xlKbFfcXAKUB2DhisZRxlRt1VAT2okzCOLGsk9UgwowW8v27S17/PamophhnxjPD3oGtfjAPvQdXeEEi6Un6G3 cloud9"
+)
+
+type Tag struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+type SSHRemote struct {
+	LoginName       string `json:"loginName"`
+	Hostname        string `json:"host"`
+	Port            int    `json:"port"`
+	EnvironmentPath string `json:"environmentPath"`
+	NodePath        string `json:"nodePath"`
+	BastionHost     string `json:"bastionHost"`
+}
+
+type Environment struct {
+	Arn            string
+	Id             string
+	Name           string
+	Description    string
+	Type           string
+	ConnectionType string
+	OwnerArn       string
+	Status         string
+	StatusMessage  string
+
+	// pendingPolls is the number of DescribeEnvironmentStatus calls left
+	// before a creating or deleting environment settles.
+	pendingPolls int
+
+	Remote *SSHRemote
+
+	// Settings is the JSON document of the environment settings.
+	Settings string
+
+	InstanceType             string
+	ImageId                  string
+	SubnetId                 string
+	AutomaticStopTimeMinutes int64
+}
+
+type Membership struct {
+	EnvironmentId string
+	Permissions   string
+	UserArn       string
+	UserId        string
+	LastAccess    *time.Time
+}
+
+// Error is returned as a JSON 1.1 error document by the server.
+type Error struct {
+	Status        int
+	ExceptionType string
+	Message       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.ExceptionType, e.Message)
+}
+
+func notFound(format string, args ...interface{}) *Error {
+	return &Error{http.StatusBadRequest, "NotFoundException", fmt.Sprintf(format, args...)}
+}
+
+func badRequest(format string, args ...interface{}) *Error {
+	return &Error{http.StatusBadRequest, "BadRequestException", fmt.Sprintf(format, args...)}
+}
+
+func conflict(format string, args ...interface{}) *Error {
+	return &Error{http.StatusBadRequest, "ConflictException", fmt.Sprintf(format, args...)}
+}
+
+type operation func(s *Server, body []byte) (interface{}, *Error)
+
+// Server is a fake Cloud9 endpoint, its state is only kept in memory.
+type Server struct {
+	*httptest.Server
+
+	Region    string
+	AccountID string
+	CallerArn string
+	PublicKey string
+
+	lock         sync.Mutex
+	environments map[string]*Environment
+	order        []string
+	memberships  map[string][]*Membership
+	tags         map[string][]Tag
+	userSettings map[string]string
+	calls        map[string]int
+	faults       map[string][]*Error
+	delays       map[string][]time.Duration
+	requestCount int
+	createPolls  int
+	deletePolls  int
+}
+
+// NewServer starts a fake Cloud9 server, it must be closed by the caller.
+func NewServer() *Server {
+	s := &Server{
+		Region:       DEFAULT_REGION,
+		AccountID:    DEFAULT_ACCOUNT_ID,
+		CallerArn:    DEFAULT_CALLER_ARN,
+		PublicKey:    DEFAULT_PUBLIC_KEY,
+		environments: make(map[string]*Environment),
+		memberships:  make(map[string][]*Membership),
+		tags:         make(map[string][]Tag),
+		userSettings: make(map[string]string),
+		calls:        make(map[string]int),
+		faults:       make(map[string][]*Error),
+		delays:       make(map[string][]time.Duration),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	rec := httptest.NewRecorder()
+	delay := s.handle(rec, r)
+
+	// the operation is applied, only the response is late
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	for key, values := range rec.Header() {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(rec.Code)
+	w.Write(rec.Body.Bytes())
+}
+
+// handle serves the request and returns the delay to wait before sending
+// its response.
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.requestCount++
+	w.Header().Set("Content-Type", AWS_JSON)
+	w.Header().Set("X-Amzn-Requestid", fmt.Sprintf("fake-request-%d", s.requestCount))
+
+	target := r.Header.Get("X-Amz-Target")
+	name := strings.TrimPrefix(target, OPERATION_PREFIX)
+	op, ok := operations[name]
+	if !ok || name == target {
+		writeError(w, &Error{http.StatusBadRequest, "UnknownOperationException", fmt.Sprintf("Unknown operation %s", target)})
+		return 0
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+		writeError(w, &Error{http.StatusForbidden, "AccessDeniedException", "Missing authentication token"})
+		return 0
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, badRequest("could not read body: %s", err))
+		return 0
+	}
+
+	s.calls[name]++
+	if faults := s.faults[name]; len(faults) > 0 {
+		s.faults[name] = faults[1:]
+		writeError(w, faults[0])
+		return 0
+	}
+
+	result, apiErr := op(s, body)
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return 0
+	}
+
+	var delay time.Duration
+	if delays := s.delays[name]; len(delays) > 0 {
+		s.delays[name] = delays[1:]
+		delay = delays[0]
+	}
+
+	json.NewEncoder(w).Encode(result)
+	return delay
+}
+
+func writeError(w http.ResponseWriter, err *Error) {
+	w.WriteHeader(err.Status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"__type":  err.ExceptionType,
+		"message": err.Message,
+	})
+}
+
+// InjectError makes the next count calls to operation fail with err.
+func (s *Server) InjectError(operation string, err *Error, count int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i := 0; i < count; i++ {
+		s.faults[operation] = append(s.faults[operation], err)
+	}
+}
+
+// InjectDelay makes the responses of the next count successful calls to
+// operation late by delay, the calls are still applied.
+func (s *Server) InjectDelay(operation string, delay time.Duration, count int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i := 0; i < count; i++ {
+		s.delays[operation] = append(s.delays[operation], delay)
+	}
+}
+
+// SetTransitionPolls makes created and deleted environments report the
+// creating and deleting statuses to the given number of
+// DescribeEnvironmentStatus calls before settling.
+func (s *Server) SetTransitionPolls(create, delete int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.createPolls = create
+	s.deletePolls = delete
+}
+
+// SetStatus sets the status of an environment out-of-band.
+func (s *Server) SetStatus(id, status, message string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if env, ok := s.environments[id]; ok {
+		env.Status = status
+		env.StatusMessage = message
+		env.pendingPolls = 0
+	}
+}
+
+// Calls returns the number of times an operation was served.
+func (s *Server) Calls(operation string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.calls[operation]
+}
+
+func newId() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func (s *Server) environmentArn(id string) string {
+	return fmt.Sprintf("arn:aws:cloud9:%s:%s:environment:%s", s.Region, s.AccountID, id)
+}
+
+func userId(userArn string) string {
+	sum := 0
+	for _, c := range userArn {
+		sum = sum*31 + int(c)
+	}
+	return fmt.Sprintf("AIDA%016X", uint64(sum))
+}
+
+func (s *Server) addEnvironment(env *Environment, tags []Tag) {
+	env.Id = newId()
+	env.Arn = s.environmentArn(env.Id)
+	if len(env.OwnerArn) == 0 {
+		env.OwnerArn = s.CallerArn
+	}
+	if len(env.Settings) == 0 {
+		env.Settings = DEFAULT_SETTINGS
+	}
+	if len(env.Status) == 0 {
+		env.Status = "ready"
+	}
+	if env.Status == "ready" && s.createPolls > 0 {
+		env.Status = "creating"
+		env.pendingPolls = s.createPolls
+	}
+
+	s.environments[env.Id] = env
+	s.order = append(s.order, env.Id)
+	s.tags[env.Arn] = append([]Tag{}, tags...)
+	s.memberships[env.Id] = []*Membership{{
+		EnvironmentId: env.Id,
+		Permissions:   "owner",
+		UserArn:       env.OwnerArn,
+		UserId:        userId(env.OwnerArn),
+	}}
+}
+
+// AddSSHEnvironment registers an SSH environment out-of-band and returns its
+// id.
+func (s *Server) AddSSHEnvironment(name string, remote SSHRemote, tags map[string]string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	env := &Environment{
+		Name:   name,
+		Type:   "ssh",
+		Remote: &remote,
+	}
+	s.addEnvironment(env, tagList(tags))
+	return env.Id
+}
+
+// AddEC2Environment registers an EC2 environment out-of-band and returns its
+// id.
+func (s *Server) AddEC2Environment(name string, tags map[string]string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	env := &Environment{
+		Name:           name,
+		Type:           "ec2",
+		ConnectionType: "CONNECT_SSH",
+		InstanceType:   "t3.small",
+		ImageId:        "amazonlinux-2023-x86_64",
+	}
+	s.addEnvironment(env, tagList(tags))
+	return env.Id
+}
+
+// Environment returns a copy of an environment, or nil if it does not exist.
+func (s *Server) Environment(id string) *Environment {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	env, ok := s.environments[id]
+	if !ok {
+		return nil
+	}
+	res := *env
+	if env.Remote != nil {
+		remote := *env.Remote
+		res.Remote = &remote
+	}
+	return &res
+}
+
+// RemoveEnvironment deletes an environment out-of-band.
+func (s *Server) RemoveEnvironment(id string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.removeEnvironment(id)
+}
+
+func (s *Server) removeEnvironment(id string) {
+	env, ok := s.environments[id]
+	if !ok {
+		return
+	}
+	delete(s.environments, id)
+	delete(s.memberships, id)
+	delete(s.tags, env.Arn)
+	for i, envId := range s.order {
+		if envId == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Tags returns the tags of an environment as a map.
+func (s *Server) Tags(id string) map[string]string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	res := make(map[string]string)
+	env, ok := s.environments[id]
+	if !ok {
+		return res
+	}
+	for _, tag := range s.tags[env.Arn] {
+		res[tag.Key] = tag.Value
+	}
+	return res
+}
+
+// SetTag sets a tag out-of-band.
+func (s *Server) SetTag(id, key, value string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if env, ok := s.environments[id]; ok {
+		s.tags[env.Arn] = mergeTags(s.tags[env.Arn], []Tag{{key, value}})
+	}
+}
+
+// UserSettings returns the settings document of the caller.
+func (s *Server) UserSettings() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.userSettingsOf(s.CallerArn)
+}
+
+// SetUserSettings sets the settings document of the caller out-of-band.
+func (s *Server) SetUserSettings(settings string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.userSettings[s.CallerArn] = settings
+}
+
+func (s *Server) userSettingsOf(userArn string) string {
+	if settings, ok := s.userSettings[userArn]; ok {
+		return settings
+	}
+	return DEFAULT_SETTINGS
+}
+
+// SetEnvironmentSettings sets the settings document of an environment
+// out-of-band.
+func (s *Server) SetEnvironmentSettings(id, settings string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if env, ok := s.environments[id]; ok {
+		env.Settings = settings
+	}
+}
+
+// AddMembership registers a membership out-of-band.
+func (s *Server) AddMembership(envId, userArn, permissions string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.memberships[envId] = append(s.memberships[envId], &Membership{
+		EnvironmentId: envId,
+		Permissions:   permissions,
+		UserArn:       userArn,
+		UserId:        userId(userArn),
+	})
+}
+
+// SetLastAccess sets the last access of a membership out-of-band.
+func (s *Server) SetLastAccess(envId, userArn string, lastAccess time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if membership := s.findMembership(envId, userArn); membership != nil {
+		membership.LastAccess = &lastAccess
+	}
+}
+
+// RemoveMembership deletes a membership out-of-band.
+func (s *Server) RemoveMembership(envId, userArn string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	memberships := s.memberships[envId]
+	for i, membership := range memberships {
+		if membership.UserArn == userArn {
+			s.memberships[envId] = append(memberships[:i:i], memberships[i+1:]...)
+			return
+		}
+	}
+}
+
+// Membership returns a copy of a membership, or nil if it does not exist.
+func (s *Server) Membership(envId, userArn string) *Membership {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if membership := s.findMembership(envId, userArn); membership != nil {
+		res := *membership
+		return &res
+	}
+	return nil
+}
+
+func (s *Server) findMembership(envId, userArn string) *Membership {
+	for _, membership := range s.memberships[envId] {
+		if membership.UserArn == userArn {
+			return membership
+		}
+	}
+	return nil
+}
+
+func tagList(tags map[string]string) []Tag {
+	res := make([]Tag, 0, len(tags))
+	for key, value := range tags {
+		res = append(res, Tag{key, value})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Key < res[j].Key })
+	return res
+}
+
+func mergeTags(tags []Tag, added []Tag) []Tag {
+	res := make(map[string]string)
+	for _, tag := range tags {
+		res[tag.Key] = tag.Value
+	}
+	for _, tag := range added {
+		res[tag.Key] = tag.Value
+	}
+	return tagList(res)
+}
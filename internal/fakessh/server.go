@@ -0,0 +1,190 @@
+// Package fakessh provides an in-process SSH server which only checks
+// public keys against per-user authorized keys and forwards direct-tcpip
+// channels, enough to act as an SSH environment host or as its bastion in
+// tests.
+package fakessh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Server is a fake SSH server listening on the loopback interface.
+type Server struct {
+	Host string
+	Port int
+
+	listener   net.Listener
+	config     *ssh.ServerConfig
+	lock       sync.Mutex
+	authorized map[string][]ssh.PublicKey
+	forwards   int
+	wg         sync.WaitGroup
+}
+
+// NewServer starts a fake SSH server, it must be closed by the caller.
+func NewServer() *Server {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(fmt.Sprintf("fakessh: could not generate the host key: %s", err))
+	}
+	signer, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		panic(fmt.Sprintf("fakessh: could not create the host key signer: %s", err))
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("fakessh: could not listen: %s", err))
+	}
+
+	s := &Server{
+		Host:       "127.0.0.1",
+		Port:       listener.Addr().(*net.TCPAddr).Port,
+		listener:   listener,
+		authorized: make(map[string][]ssh.PublicKey),
+	}
+	s.config = &ssh.ServerConfig{
+		PublicKeyCallback: s.checkPublicKey,
+	}
+	s.config.AddHostKey(signer)
+
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Addr returns the host:port address of the server.
+func (s *Server) Addr() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// Authorize adds a key, in the authorized_keys format, to the authorized
+// keys of user.
+func (s *Server) Authorize(user string, authorizedKey string) {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(authorizedKey))
+	if err != nil {
+		panic(fmt.Sprintf("fakessh: invalid authorized key: %s", err))
+	}
+	s.AuthorizeKey(user, key)
+}
+
+// AuthorizeKey adds a key to the authorized keys of user.
+func (s *Server) AuthorizeKey(user string, key ssh.PublicKey) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.authorized[user] = append(s.authorized[user], key)
+}
+
+// Forwards returns the number of direct-tcpip channels opened so far.
+func (s *Server) Forwards() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.forwards
+}
+
+// Close stops the server and waits for the connections to be closed.
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) checkPublicKey(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, authorized := range s.authorized[conn.User()] {
+		if bytes.Equal(authorized.Marshal(), key.Marshal()) {
+			return &ssh.Permissions{}, nil
+		}
+	}
+	return nil, fmt.Errorf("key not authorized for user %s", conn.User())
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	defer serverConn.Close()
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			newChannel.Reject(ssh.UnknownChannelType, "only direct-tcpip channels are supported")
+			continue
+		}
+		go s.forward(newChannel)
+	}
+}
+
+// forward connects a direct-tcpip channel to its destination.
+func (s *Server) forward(newChannel ssh.NewChannel) {
+	var payload struct {
+		Host       string
+		Port       uint32
+		OriginHost string
+		OriginPort uint32
+	}
+	if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, "invalid payload")
+		return
+	}
+
+	target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))))
+	if err != nil {
+		newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	defer target.Close()
+
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer channel.Close()
+	go ssh.DiscardRequests(requests)
+
+	s.lock.Lock()
+	s.forwards++
+	s.lock.Unlock()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(target, channel)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(channel, target)
+		done <- struct{}{}
+	}()
+	<-done
+}
@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const DEFAULT_SSH_PORT = 22
+
+// bastionHost is a parsed bastion url, whose wire format is
+// `[user@]host[:port]`.
+type bastionHost struct {
+	User string
+	Host string
+	Port int
+}
+
+type bastionModel struct {
+	User types.String `tfsdk:"user"`
+	Host types.String `tfsdk:"host"`
+	Port types.Int64  `tfsdk:"port"`
+}
+
+func parseBastionURL(url string) (bastionHost, error) {
+	res := bastionHost{Port: DEFAULT_SSH_PORT}
+	if strings.Contains(url, "://") {
+		return res, fmt.Errorf("the url must not have a scheme")
+	}
+
+	hostPort := url
+	if i := strings.LastIndex(url, "@"); i >= 0 {
+		res.User = url[:i]
+		hostPort = url[i+1:]
+		if len(res.User) == 0 || strings.ContainsAny(res.User, "@:/ ") {
+			return res, fmt.Errorf("invalid user %q", res.User)
+		}
+	}
+
+	res.Host = hostPort
+	if strings.HasPrefix(hostPort, "[") || strings.Count(hostPort, ":") == 1 {
+		host, port, err := net.SplitHostPort(hostPort)
+		if err != nil {
+			return res, err
+		}
+		res.Host = host
+		if res.Port, err = strconv.Atoi(port); err != nil || res.Port < 1 || res.Port > 65535 {
+			return res, fmt.Errorf("invalid port %q", port)
+		}
+	}
+
+	if len(res.Host) == 0 || strings.ContainsAny(res.Host, "@/ ") {
+		return res, fmt.Errorf("invalid host %q", res.Host)
+	}
+	res.Host = strings.ToLower(res.Host)
+
+	return res, nil
+}
+
+// String returns the wire format of the bastion, the port is always set.
+func (b bastionHost) String() string {
+	hostPort := net.JoinHostPort(b.Host, strconv.Itoa(b.Port))
+	if len(b.User) > 0 {
+		return b.User + "@" + hostPort
+	}
+	return hostPort
+}
+
+// bastionURLsEqual reports whether two bastion urls point to the same
+// bastion, such as `user@host` and `user@host:22`.
+func bastionURLsEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	parsedA, err := parseBastionURL(a)
+	if err != nil {
+		return false
+	}
+	parsedB, err := parseBastionURL(b)
+	if err != nil {
+		return false
+	}
+	return parsedA == parsedB
+}
+
+func (m *bastionModel) bastionHost() bastionHost {
+	res := bastionHost{
+		User: m.User.ValueString(),
+		Host: strings.ToLower(m.Host.ValueString()),
+		Port: DEFAULT_SSH_PORT,
+	}
+	if !m.Port.IsNull() {
+		res.Port = int(m.Port.ValueInt64())
+	}
+	return res
+}
+
+// bastionToModel returns the bastion block from a bastion url, the port is
+// only set when it was set in the current block or is not the default one.
+func bastionToModel(url string, current *bastionModel) (*bastionModel, error) {
+	bastion, err := parseBastionURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &bastionModel{
+		User: types.StringNull(),
+		Host: types.StringValue(bastion.Host),
+		Port: types.Int64Null(),
+	}
+	if len(bastion.User) > 0 {
+		res.User = types.StringValue(bastion.User)
+	}
+	if bastion.Port != DEFAULT_SSH_PORT || (current != nil && !current.Port.IsNull()) {
+		res.Port = types.Int64Value(int64(bastion.Port))
+	}
+	if current != nil && strings.EqualFold(current.Host.ValueString(), bastion.Host) {
+		res.Host = current.Host
+	}
+	return res, nil
+}
+
+var _ validator.String = bastionURLValidator{}
+
+type bastionURLValidator struct{}
+
+func (v bastionURLValidator) Description(ctx context.Context) string {
+	return "value must be a bastion url formatted like [user@]host[:port]"
+}
+
+func (v bastionURLValidator) MarkdownDescription(ctx context.Context) string {
+	return "value must be a bastion url formatted like `[user@]host[:port]`"
+}
+
+func (v bastionURLValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if _, err := parseBastionURL(value); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid bastion URL",
+			fmt.Sprintf("%q is not a valid bastion url, %s: %s.", value, v.Description(ctx), err.Error()))
+	}
+}
+
+// validateBastionURL checks that a string follows the `[user@]host[:port]`
+// format expected by cloud9.
+func validateBastionURL() validator.String {
+	return bastionURLValidator{}
+}
+
+var _ planmodifier.String = bastionURLPlanModifier{}
+
+// bastionURLPlanModifier plans bastion_url from the bastion block when it
+// is not configured, and keeps the state value when the configured one is
+// equivalent.
+type bastionURLPlanModifier struct{}
+
+func (m bastionURLPlanModifier) Description(ctx context.Context) string {
+	return "Computes the bastion url from the bastion block and ignores equivalent urls."
+}
+
+func (m bastionURLPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m bastionURLPlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.Plan.Raw.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if !req.ConfigValue.IsNull() {
+		if !req.StateValue.IsNull() && bastionURLsEqual(req.StateValue.ValueString(), req.ConfigValue.ValueString()) {
+			resp.PlanValue = req.StateValue
+		}
+		return
+	}
+
+	var bastion *bastionModel
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("bastion"), &bastion)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if bastion == nil {
+		resp.PlanValue = types.StringNull()
+		return
+	}
+	if bastion.User.IsUnknown() || bastion.Host.IsUnknown() || bastion.Port.IsUnknown() {
+		resp.PlanValue = types.StringUnknown()
+		return
+	}
+
+	url := bastion.bastionHost().String()
+	if !req.StateValue.IsNull() && bastionURLsEqual(req.StateValue.ValueString(), url) {
+		resp.PlanValue = req.StateValue
+		return
+	}
+	resp.PlanValue = types.StringValue(url)
+}
@@ -0,0 +1,63 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestParseBastionURL(t *testing.T) {
+	urls := map[string]*bastionHost{
+		"jump@bastion.example.com:2222":  {User: "jump", Host: "bastion.example.com", Port: 2222},
+		"jump@Bastion.example.com":       {User: "jump", Host: "bastion.example.com", Port: 22},
+		"bastion.example.com":            {Host: "bastion.example.com", Port: 22},
+		"10.0.0.1:22":                    {Host: "10.0.0.1", Port: 22},
+		"jump@[2001:db8::1]:2222":        {User: "jump", Host: "2001:db8::1", Port: 2222},
+		"jump@2001:db8::1":               {User: "jump", Host: "2001:db8::1", Port: 22},
+		"ssh://jump@bastion.example.com": nil,
+		"@bastion.example.com":           nil,
+		"jump@":                          nil,
+		"jump@bastion.example.com:0":     nil,
+		"jump@bastion.example.com:ssh":   nil,
+		"jump@bastion.example.com:70000": nil,
+		"jump@bastion/path":              nil,
+		"":                               nil,
+	}
+
+	for url, expected := range urls {
+		bastion, err := parseBastionURL(url)
+		if expected == nil {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %+v", url, bastion)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", url, err)
+		} else if bastion != *expected {
+			t.Errorf("%q: expected %+v, got %+v", url, *expected, bastion)
+		}
+	}
+}
+
+func TestBastionURLsEqual(t *testing.T) {
+	pairs := []struct {
+		a, b  string
+		equal bool
+	}{
+		{"jump@bastion.example.com", "jump@bastion.example.com:22", true},
+		{"jump@Bastion.example.com:22", "jump@bastion.example.com", true},
+		{"jump@bastion.example.com", "jump@bastion.example.com:2222", false},
+		{"jump@bastion.example.com", "bastion.example.com", false},
+		{"jump@[2001:db8::1]:22", "jump@2001:db8::1", true},
+	}
+
+	for _, pair := range pairs {
+		if bastionURLsEqual(pair.a, pair.b) != pair.equal {
+			t.Errorf("%q and %q: expected equal=%t", pair.a, pair.b, pair.equal)
+		}
+	}
+
+	bastion := bastionHost{User: "jump", Host: "bastion.example.com", Port: 22}
+	if bastion.String() != "jump@bastion.example.com:22" {
+		t.Errorf("unexpected wire format %s", bastion.String())
+	}
+}
@@ -0,0 +1,382 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/cloud9"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
+)
+
+var (
+	_ resource.Resource                = &EC2EnvironmentResource{}
+	_ resource.ResourceWithConfigure   = &EC2EnvironmentResource{}
+	_ resource.ResourceWithImportState = &EC2EnvironmentResource{}
+	_ resource.ResourceWithModifyPlan  = &EC2EnvironmentResource{}
+)
+
+type EC2EnvironmentResource struct {
+	client *aws.AWSCloud9Client
+}
+
+func NewEC2EnvironmentResource() resource.Resource {
+	return &EC2EnvironmentResource{}
+}
+
+type EC2EnvironmentResourceModel = EC2EnvironmentModel
+
+// IMPORTED_PRIVATE_KEY marks the imported environments in the private state
+// until their first update. The instance settings are not returned by the
+// API, so they are null in the state of imported environments.
+const IMPORTED_PRIVATE_KEY = "imported"
+
+// replaceUnlessImported requires the replacement of the environment when an
+// instance setting changes, unless it is set for the first time after an
+// import, in which case only the state is updated.
+func replaceUnlessImported(ctx context.Context, stateValue attr.Value, private interface {
+	GetKey(context.Context, string) ([]byte, diag.Diagnostics)
+}) (bool, diag.Diagnostics) {
+	imported, diags := private.GetKey(ctx, IMPORTED_PRIVATE_KEY)
+	return string(imported) != "true" || !stateValue.IsNull(), diags
+}
+
+func stringReplaceUnlessImported() planmodifier.String {
+	return stringplanmodifier.RequiresReplaceIf(func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+		var diags diag.Diagnostics
+		resp.RequiresReplace, diags = replaceUnlessImported(ctx, req.StateValue, req.Private)
+		resp.Diagnostics.Append(diags...)
+	}, "Changing the value requires the replacement of the environment, unless it was imported",
+		"Changing the value requires the replacement of the environment, unless it was imported")
+}
+
+func int64ReplaceUnlessImported() planmodifier.Int64 {
+	return int64planmodifier.RequiresReplaceIf(func(ctx context.Context, req planmodifier.Int64Request, resp *int64planmodifier.RequiresReplaceIfFuncResponse) {
+		var diags diag.Diagnostics
+		resp.RequiresReplace, diags = replaceUnlessImported(ctx, req.StateValue, req.Private)
+		resp.Diagnostics.Append(diags...)
+	}, "Changing the value requires the replacement of the environment, unless it was imported",
+		"Changing the value requires the replacement of the environment, unless it was imported")
+}
+
+func (rs *EC2EnvironmentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_ec2_environment"
+}
+
+func (rs *EC2EnvironmentResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Creates a cloud 9 EC2 environment. The instance settings, `instance_type`, `image_id`, `subnet_id` and `automatic_stop_time_minutes`, are not returned by the API and are never read back, changes made outside of terraform are not detected. After an import, the first apply copies them from the configuration to the state without replacing the environment and without checking them against the environment, so they must match the settings it was created with. Any later change replaces the environment.",
+		Attributes: map[string]schema.Attribute{
+			"arn": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The arn of the environment",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The id of the environment",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"name": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The name of the environment",
+			},
+			"description": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The description of the environment",
+			},
+			"instance_type": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The type of instance to connect to the environment, e.g. `t2.micro`",
+				PlanModifiers:       []planmodifier.String{stringReplaceUnlessImported()},
+			},
+			"image_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The identifier or SSM path of the AMI used to create the instance, e.g. `amazonlinux-2023-x86_64`",
+				PlanModifiers:       []planmodifier.String{stringReplaceUnlessImported()},
+			},
+			"subnet_id": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The id of the subnet to launch the instance into",
+				PlanModifiers:       []planmodifier.String{stringReplaceUnlessImported()},
+			},
+			"connection_type": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(cloud9.ConnectionTypeConnectSsh),
+				MarkdownDescription: "The connection type used to connect to the instance, can be one of `CONNECT_SSH` and `CONNECT_SSM`",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			},
+			"automatic_stop_time_minutes": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "The number of minutes until the instance is stopped after the environment was last used",
+				PlanModifiers:       []planmodifier.Int64{int64ReplaceUnlessImported()},
+			},
+			"owner_arn": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "The arn of the owner of the environment, defaults to the caller",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"tags": schema.MapAttribute{
+				MarkdownDescription: "A list of tags to attach",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"tags_all": schema.MapAttribute{
+				MarkdownDescription: "The tags of the environment, including the provider `default_tags`",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (rs *EC2EnvironmentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*aws.AWSCloud9Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *aws.AWSCloud9Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	rs.client = client
+}
+
+func (rs *EC2EnvironmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan EC2EnvironmentResourceModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tags, diags := tagsFromMap(ctx, plan.TagsAll)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	input := &cloud9.CreateEnvironmentEC2Input{
+		Name:           plan.Name.ValueStringPointer(),
+		InstanceType:   plan.InstanceType.ValueStringPointer(),
+		ImageId:        plan.ImageID.ValueStringPointer(),
+		ConnectionType: plan.ConnectionType.ValueStringPointer(),
+	}
+	if !plan.Description.IsNull() {
+		input.Description = plan.Description.ValueStringPointer()
+	}
+	if !plan.SubnetID.IsNull() {
+		input.SubnetId = plan.SubnetID.ValueStringPointer()
+	}
+	if !plan.AutomaticStopTimeMinutes.IsNull() {
+		input.AutomaticStopTimeMinutes = plan.AutomaticStopTimeMinutes.ValueInt64Pointer()
+	}
+	if !plan.OwnerArn.IsUnknown() && !plan.OwnerArn.IsNull() {
+		input.OwnerArn = plan.OwnerArn.ValueStringPointer()
+	}
+	for i := range tags {
+		input.Tags = append(input.Tags, &cloud9.Tag{
+			Key:   &tags[i].Key,
+			Value: &tags[i].Value,
+		})
+	}
+
+	envId, err := rs.client.CreateEnvironmentEC2(ctx, input)
+	if aws.IsConflict(err) {
+		resp.Diagnostics.AddError("Environment already exists", fmt.Sprintf("Unable to create environment %s, an environment with the same name already exists: %s", plan.Name.ValueString(), err.Error()))
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Unable to create environment %s, got error: %s", plan.Name.ValueString(), err))
+		return
+	}
+
+	plan.ID = types.StringValue(envId)
+	err = rs.client.WaitEnvironmentReady(ctx, envId)
+	if err != nil {
+		// keep the environment in the state so that it gets tainted
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), envId)...)
+		resp.Diagnostics.AddError("Environment creation failed", fmt.Sprintf("Environment %s did not become ready: %s", envId, err.Error()))
+		return
+	}
+
+	readResults, err := rs.client.GetEC2Environments(ctx, envId)
+	if err != nil {
+		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Could not read environment %s: %s", envId, err.Error()))
+		return
+	} else if len(readResults) == 0 {
+		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Could not read environment %s", envId))
+		return
+	}
+	readResult := readResults[0]
+	plan.Arn = types.StringValue(readResult.Arn)
+	plan.OwnerArn = types.StringValue(readResult.OwnerArn)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (rs *EC2EnvironmentResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	modifyPlanTagsAll(ctx, rs.client, req, resp)
+}
+
+// convertEC2EnvironmentToModel only updates the attributes returned by
+// DescribeEnvironments, the instance settings are kept from the state.
+func convertEC2EnvironmentToModel(ctx context.Context, client *aws.AWSCloud9Client, state *EC2EnvironmentResourceModel, environment *aws.Cloud9EC2Environment) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	state.Arn = types.StringValue(environment.Arn)
+	state.ID = types.StringValue(environment.EnvironmentId)
+	state.Name = types.StringValue(environment.Name)
+	if len(environment.Description) > 0 {
+		state.Description = types.StringValue(environment.Description)
+	} else {
+		state.Description = types.StringNull()
+	}
+	if len(environment.ConnectionType) > 0 {
+		state.ConnectionType = types.StringValue(environment.ConnectionType)
+	}
+	state.OwnerArn = types.StringValue(environment.OwnerArn)
+
+	state.Tags, state.TagsAll, diags = readTags(ctx, client, environment.Tags, state.Tags)
+
+	return diags
+}
+
+func (rs *EC2EnvironmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state EC2EnvironmentResourceModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	envId := state.ID.ValueString()
+	environments, err := rs.client.GetEC2Environments(ctx, envId)
+	if err != nil && !aws.IsNotFound(err) {
+		resp.Diagnostics.AddError("Error fetching env", fmt.Sprintf("Could not fetch env %s: %s", envId, err.Error()))
+		return
+	}
+
+	if len(environments) == 0 {
+		// the environment was deleted outside of terraform
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	environment := environments[0]
+	if environment.Type != cloud9.EnvironmentTypeEc2 {
+		resp.Diagnostics.AddError("Not an EC2 environment", fmt.Sprintf("Environment %s is of type %s", envId, environment.Type))
+		return
+	}
+
+	diags = convertEC2EnvironmentToModel(ctx, rs.client, &state, &environment)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (rs *EC2EnvironmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan EC2EnvironmentResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state EC2EnvironmentResourceModel
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	envId := state.ID.ValueString()
+	arn := state.Arn.ValueString()
+
+	err := rs.client.UpdateEC2Environment(ctx, aws.Cloud9EC2Environment{
+		EnvironmentId: envId,
+		Name:          plan.Name.ValueString(),
+		Description:   plan.Description.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating environment", fmt.Sprintf("Error updating environment %s: %s", envId, err.Error()))
+		return
+	}
+
+	resp.Diagnostics.Append(updateTags(ctx, rs.client, envId, arn, state.TagsAll, plan.TagsAll)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the instance settings are now in the state
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, IMPORTED_PRIVATE_KEY, []byte("false"))...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (rs *EC2EnvironmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state EC2EnvironmentResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	envId := state.ID.ValueString()
+	err := rs.client.DeleteEnvironment(ctx, envId)
+	if aws.IsNotFound(err) {
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError("Error deleting env", fmt.Sprintf("Could not delete environment %s: %s", envId, err.Error()))
+		return
+	}
+
+	err = rs.client.WaitEnvironmentDeleted(ctx, envId)
+	if err != nil {
+		resp.Diagnostics.AddError("Error deleting env", fmt.Sprintf("Environment %s was not deleted: %s", envId, err.Error()))
+		return
+	}
+}
+
+func (rs *EC2EnvironmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, IMPORTED_PRIVATE_KEY, []byte("true"))...)
+}
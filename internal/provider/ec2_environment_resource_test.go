@@ -0,0 +1,135 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func TestAccEC2EnvironmentResource(t *testing.T) {
+	server := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + `
+resource "awscloud9_ec2_environment" "test" {
+  name          = "env"
+  instance_type = "t3.small"
+  image_id      = "amazonlinux-2023-x86_64"
+
+  tags = {
+    team = "infra"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("awscloud9_ec2_environment.test", "id"),
+					resource.TestCheckResourceAttrSet("awscloud9_ec2_environment.test", "arn"),
+					resource.TestCheckResourceAttr("awscloud9_ec2_environment.test", "connection_type", "CONNECT_SSH"),
+					resource.TestCheckResourceAttr("awscloud9_ec2_environment.test", "owner_arn", fakecloud9.DEFAULT_CALLER_ARN),
+					resource.TestCheckResourceAttr("awscloud9_ec2_environment.test", "tags.team", "infra"),
+					resource.TestCheckResourceAttr("awscloud9_ec2_environment.test", "tags_all.team", "infra"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(server) + `
+resource "awscloud9_ec2_environment" "test" {
+  name          = "renamed"
+  description   = "an environment"
+  instance_type = "t3.small"
+  image_id      = "amazonlinux-2023-x86_64"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_ec2_environment.test", "name", "renamed"),
+					resource.TestCheckResourceAttr("awscloud9_ec2_environment.test", "description", "an environment"),
+					resource.TestCheckNoResourceAttr("awscloud9_ec2_environment.test", "tags.%"),
+				),
+			},
+			{
+				ResourceName:      "awscloud9_ec2_environment.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				// not returned by the API, set from the configuration on the next apply
+				ImportStateVerifyIgnore: []string{"instance_type", "image_id"},
+			},
+		},
+	})
+}
+
+func TestAccEC2EnvironmentResourceImport(t *testing.T) {
+	server := testAccServer(t)
+	envId := server.AddEC2Environment("env", nil)
+	config := testAccProviderConfig(server) + `
+resource "awscloud9_ec2_environment" "test" {
+  name          = "env"
+  instance_type = "%s"
+  image_id      = "amazonlinux-2023-x86_64"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:             fmt.Sprintf(config, "t3.small"),
+				ResourceName:       "awscloud9_ec2_environment.test",
+				ImportState:        true,
+				ImportStateId:      envId,
+				ImportStatePersist: true,
+			},
+			{
+				Config: fmt.Sprintf(config, "t3.small"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("awscloud9_ec2_environment.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_ec2_environment.test", "id", envId),
+					resource.TestCheckResourceAttr("awscloud9_ec2_environment.test", "instance_type", "t3.small"),
+					resource.TestCheckResourceAttr("awscloud9_ec2_environment.test", "image_id", "amazonlinux-2023-x86_64"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(config, "t3.medium"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("awscloud9_ec2_environment.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("awscloud9_ec2_environment.test", "instance_type", "t3.medium"),
+			},
+		},
+	})
+}
+
+func TestAccEC2EnvironmentResourceWrongType(t *testing.T) {
+	server := testAccServer(t)
+	envId := server.AddSSHEnvironment("ssh", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com", Port: 22}, nil)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + `
+resource "awscloud9_ec2_environment" "test" {
+  name          = "ssh"
+  instance_type = "t3.small"
+  image_id      = "amazonlinux-2023-x86_64"
+}
+`,
+				ResourceName:  "awscloud9_ec2_environment.test",
+				ImportState:   true,
+				ImportStateId: envId,
+				ExpectError:   regexp.MustCompile("Not an EC2 environment"),
+			},
+		},
+	})
+}
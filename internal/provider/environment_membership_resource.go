@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
 )
@@ -54,10 +55,16 @@ func (rs *EnvironmentMembershipResource) Schema(ctx context.Context, req resourc
 			"permissions": schema.StringAttribute{
 				Required:            true,
 				MarkdownDescription: "The permissions to give to the role, can be one of `read-write` and `read-only`",
+				Validators: []validator.String{
+					validateMemberPermissions(),
+				},
 			},
 			"user_arn": schema.StringAttribute{
 				Required:            true,
 				MarkdownDescription: "The arn of the aws resource that will be given membership to the environment",
+				Validators: []validator.String{
+					validateUserArn(),
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
@@ -91,12 +98,18 @@ func (rs *EnvironmentMembershipResource) Create(ctx context.Context, req resourc
 	}
 
 	envId := plan.EnvironmentId.ValueString()
-	_, err := rs.client.Cloud9.CreateEnvironmentMembership(&cloud9.CreateEnvironmentMembershipInput{
+	_, err := rs.client.Cloud9.CreateEnvironmentMembershipWithContext(ctx, &cloud9.CreateEnvironmentMembershipInput{
 		EnvironmentId: &envId,
 		UserArn:       plan.UserARN.ValueStringPointer(),
 		Permissions:   plan.Permissions.ValueStringPointer(),
 	})
-	if err != nil {
+	if aws.IsConflict(err) {
+		resp.Diagnostics.AddError("Membership already exists", fmt.Sprintf("User %s is already a member of environment %s, the membership can be imported with `terraform import`: %s", plan.UserARN.String(), plan.EnvironmentId.String(), err.Error()))
+		return
+	} else if aws.IsNotFound(err) {
+		resp.Diagnostics.AddError("Environment not found", fmt.Sprintf("Could not create membership for user %s, environment %s does not exist: %s", plan.UserARN.String(), plan.EnvironmentId.String(), err.Error()))
+		return
+	} else if err != nil {
 		resp.Diagnostics.AddError("Error creating environment membership", fmt.Sprintf("An error occured creating membership for environment %s, for user %s: %s", plan.EnvironmentId.String(), plan.UserARN.String(), err.Error()))
 		return
 	}
@@ -120,9 +133,13 @@ func (rs *EnvironmentMembershipResource) Read(ctx context.Context, req resource.
 	envId := state.EnvironmentId.ValueString()
 	userArn := state.UserARN.ValueString()
 
-	environments, err := rs.client.GetMemberShips(envId)
-	if err != nil {
-		resp.Diagnostics.AddError("Error fetching memberships", fmt.Sprintf("Could not retrieve memberships for environment %s", state.EnvironmentId.String()))
+	environments, err := rs.client.GetMemberShips(ctx, envId)
+	if aws.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError("Error fetching memberships", fmt.Sprintf("Could not retrieve memberships for environment %s: %s", state.EnvironmentId.String(), err.Error()))
+		return
 	}
 
 	var foundEnv *aws.Cloud9EnvironmentMembership = nil
@@ -134,7 +151,8 @@ func (rs *EnvironmentMembershipResource) Read(ctx context.Context, req resource.
 	}
 
 	if foundEnv == nil {
-		resp.Diagnostics.AddError("Error fetching memberships", fmt.Sprintf("Could not retrieve membership for environment %s, for user %s", state.EnvironmentId.String(), state.UserARN.String()))
+		// the user was removed from the environment outside of terraform
+		resp.State.RemoveResource(ctx)
 		return
 	}
 
@@ -159,41 +177,62 @@ func (rs *EnvironmentMembershipResource) Delete(ctx context.Context, req resourc
 	envId := state.EnvironmentId.ValueString()
 	userArn := state.UserARN.ValueString()
 
-	_, err := rs.client.Cloud9.DeleteEnvironmentMembership(&cloud9.DeleteEnvironmentMembershipInput{
+	_, err := rs.client.Cloud9.DeleteEnvironmentMembershipWithContext(ctx, &cloud9.DeleteEnvironmentMembershipInput{
 		EnvironmentId: &envId,
 		UserArn:       &userArn,
 	})
 
-	if err != nil {
+	if err != nil && !aws.IsNotFound(err) {
 		resp.Diagnostics.AddError("Error deleting membership", fmt.Sprintf("Could not delete membership for environment %s for user %s: %s", envId, state.UserARN.String(), err.Error()))
 		return
 	}
 }
 
 func (rs *EnvironmentMembershipResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var state environmentMembershipModel
+	var plan environmentMembershipModel
 
-	diags := req.State.Get(ctx, &state)
+	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	envId := state.EnvironmentId.ValueString()
-	userArn := state.UserARN.ValueString()
+	envId := plan.EnvironmentId.ValueString()
+	userArn := plan.UserARN.ValueString()
 
-	_, err := rs.client.Cloud9.UpdateEnvironmentMembership(&cloud9.UpdateEnvironmentMembershipInput{
+	_, err := rs.client.Cloud9.UpdateEnvironmentMembershipWithContext(ctx, &cloud9.UpdateEnvironmentMembershipInput{
 		EnvironmentId: &envId,
 		UserArn:       &userArn,
-		Permissions:   state.Permissions.ValueStringPointer(),
+		Permissions:   plan.Permissions.ValueStringPointer(),
 	})
 
 	if err != nil {
-		resp.Diagnostics.AddError("Error updating membership", fmt.Sprintf("Could not update membership for environment %s for user %s: %s", envId, state.UserARN.String(), err.Error()))
+		resp.Diagnostics.AddError("Error updating membership", fmt.Sprintf("Could not update membership for environment %s for user %s: %s", envId, plan.UserARN.String(), err.Error()))
 		return
 	}
 
-	diags = resp.State.Set(ctx, &state)
+	memberships, err := rs.client.GetMemberShips(ctx, envId)
+	if err != nil {
+		resp.Diagnostics.AddError("Error fetching memberships", fmt.Sprintf("Could not retrieve memberships for environment %s: %s", plan.EnvironmentId.String(), err.Error()))
+		return
+	}
+
+	var foundEnv *aws.Cloud9EnvironmentMembership = nil
+	for i := range memberships {
+		if memberships[i].UserARN == userArn {
+			foundEnv = &memberships[i]
+			break
+		}
+	}
+
+	if foundEnv == nil {
+		resp.Diagnostics.AddError("Error fetching memberships", fmt.Sprintf("Could not retrieve membership for environment %s, for user %s after the update", plan.EnvironmentId.String(), plan.UserARN.String()))
+		return
+	}
+
+	plan.Permissions = types.StringValue(foundEnv.Permissions)
+
+	diags = resp.State.Set(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+const testAccMemberArn = "arn:aws:iam::123456789012:user/member"
+
+func testAccMembershipConfig(permissions string) string {
+	return fmt.Sprintf(`
+resource "awscloud9_ssh_environment" "test" {
+  name       = "env"
+  login_name = "ubuntu"
+  hostname   = "example.com"
+}
+
+resource "awscloud9_environment_membership" "test" {
+  environment_id = awscloud9_ssh_environment.test.id
+  permissions    = %q
+  user_arn       = %q
+}
+`, permissions, testAccMemberArn)
+}
+
+func TestAccEnvironmentMembershipResource(t *testing.T) {
+	server := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + testAccMembershipConfig("read-only"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("awscloud9_environment_membership.test", "environment_id", "awscloud9_ssh_environment.test", "id"),
+					resource.TestCheckResourceAttr("awscloud9_environment_membership.test", "permissions", "read-only"),
+					resource.TestCheckResourceAttr("awscloud9_environment_membership.test", "user_arn", testAccMemberArn),
+				),
+			},
+			{
+				ResourceName: "awscloud9_environment_membership.test",
+				ImportState:  true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					rs := s.RootModule().Resources["awscloud9_environment_membership.test"]
+					return rs.Primary.Attributes["environment_id"] + ":" + rs.Primary.Attributes["user_arn"], nil
+				},
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "user_arn",
+			},
+		},
+	})
+}
+
+func TestAccEnvironmentMembershipResourceUpdate(t *testing.T) {
+	server := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + testAccMembershipConfig("read-only"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_environment_membership.test", "permissions", "read-only"),
+					testAccCheckMembership(server, "awscloud9_environment_membership.test", "read-only"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccMembershipConfig("read-write"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_environment_membership.test", "permissions", "read-write"),
+					testAccCheckMembership(server, "awscloud9_environment_membership.test", "read-write"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccMembershipConfig("read-only"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_environment_membership.test", "permissions", "read-only"),
+					testAccCheckMembership(server, "awscloud9_environment_membership.test", "read-only"),
+				),
+			},
+		},
+	})
+}
+
+// testAccCheckMembership checks the permissions stored by the fake server.
+func testAccCheckMembership(server *fakecloud9.Server, name, permissions string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource %s not found", name)
+		}
+
+		membership := server.Membership(rs.Primary.Attributes["environment_id"], rs.Primary.Attributes["user_arn"])
+		if membership == nil {
+			return fmt.Errorf("membership of %s does not exist", rs.Primary.Attributes["user_arn"])
+		}
+		if membership.Permissions != permissions {
+			return fmt.Errorf("expected %s permissions, got %s", permissions, membership.Permissions)
+		}
+		return nil
+	}
+}
+
+func TestAccEnvironmentMembershipResourceDisappears(t *testing.T) {
+	server := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:             testAccProviderConfig(server) + testAccMembershipConfig("read-only"),
+				Check:              testAccCheckMembershipDisappears(server, "awscloud9_environment_membership.test"),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config:             testAccProviderConfig(server) + testAccMembershipConfig("read-only"),
+				Check:              testAccCheckSSHEnvironmentDisappears(server, "awscloud9_ssh_environment.test"),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// testAccCheckMembershipDisappears removes the member behind terraform's back.
+func testAccCheckMembershipDisappears(server *fakecloud9.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource %s not found", name)
+		}
+
+		server.RemoveMembership(rs.Primary.Attributes["environment_id"], rs.Primary.Attributes["user_arn"])
+		return nil
+	}
+}
+
+func TestAccEnvironmentMembershipResourceValidation(t *testing.T) {
+	server := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderConfig(server) + testAccMembershipConfig("owner"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Invalid Attribute Value Match"),
+			},
+			{
+				Config: testAccProviderConfig(server) + `
+resource "awscloud9_environment_membership" "test" {
+  environment_id = "env"
+  permissions    = "read-only"
+  user_arn       = "arn:aws:iam::123456789012:role/member"
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Invalid user ARN"),
+			},
+		},
+	})
+}
+
+func TestAccEnvironmentMembershipResourceCacheReads(t *testing.T) {
+	server := testAccServer(t)
+
+	config := testAccProviderConfigWithBlocks(server, "\n  cache_reads = true\n") + `
+resource "awscloud9_ssh_environment" "test" {
+  name       = "env"
+  login_name = "ubuntu"
+  hostname   = "example.com"
+}
+
+resource "awscloud9_environment_membership" "test" {
+  count = 5
+
+  environment_id = awscloud9_ssh_environment.test.id
+  permissions    = "read-only"
+  user_arn       = "arn:aws:iam::123456789012:user/member-${count.index}"
+}
+`
+
+	var calls int
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr("awscloud9_environment_membership.test.4", "permissions", "read-only"),
+			},
+			{
+				PreConfig: func() {
+					calls = server.Calls("DescribeEnvironmentMemberships")
+				},
+				Config:   config,
+				PlanOnly: true,
+			},
+			{
+				// the memberships are read once per terraform command instead
+				// of once per membership, a plan only step runs two plans
+				PreConfig: func() {
+					if planCalls := server.Calls("DescribeEnvironmentMemberships") - calls; planCalls != 2 {
+						t.Errorf("expected 2 DescribeEnvironmentMemberships calls with the cache, the plan made %d", planCalls)
+					}
+				},
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
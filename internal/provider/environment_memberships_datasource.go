@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
+)
+
+var _ datasource.DataSource = &EnvironmentMembershipsDataSource{}
+
+func NewEnvironmentMembershipsDataSource() datasource.DataSource {
+	return &EnvironmentMembershipsDataSource{}
+}
+
+type EnvironmentMembershipsDataSource struct {
+	client *aws.AWSCloud9Client
+}
+
+type membershipModel struct {
+	Permissions types.String `tfsdk:"permissions"`
+	UserARN     types.String `tfsdk:"user_arn"`
+	UserID      types.String `tfsdk:"user_id"`
+	LastAccess  types.String `tfsdk:"last_access"`
+}
+
+type EnvironmentMembershipsDataSourceModel struct {
+	EnvironmentId types.String      `tfsdk:"environment_id"`
+	Permissions   types.String      `tfsdk:"permissions"`
+	UserARN       types.String      `tfsdk:"user_arn"`
+	Memberships   []membershipModel `tfsdk:"memberships"`
+}
+
+func (ds *EnvironmentMembershipsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_environment_memberships"
+}
+
+func (ds *EnvironmentMembershipsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the members of a cloud 9 environment, including its owner",
+		Attributes: map[string]schema.Attribute{
+			"environment_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the environment",
+				Required:            true,
+			},
+			"permissions": schema.StringAttribute{
+				MarkdownDescription: "Only return the members with these permissions, one of `owner`, `read-write` or `read-only`",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(aws.OWNER, aws.READ_WRITE, aws.READONLY),
+				},
+			},
+			"user_arn": schema.StringAttribute{
+				MarkdownDescription: "Only return the membership of this user",
+				Optional:            true,
+				Validators: []validator.String{
+					validateUserArn(),
+				},
+			},
+			"memberships": schema.ListNestedAttribute{
+				MarkdownDescription: "The memberships of the environment",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"permissions": schema.StringAttribute{
+							MarkdownDescription: "The permissions of the user",
+							Computed:            true,
+						},
+						"user_arn": schema.StringAttribute{
+							MarkdownDescription: "The ARN of the user",
+							Computed:            true,
+						},
+						"user_id": schema.StringAttribute{
+							MarkdownDescription: "The IAM id of the user",
+							Computed:            true,
+						},
+						"last_access": schema.StringAttribute{
+							MarkdownDescription: "The last time the user opened the environment, formatted as RFC3339. Null if the user never opened it",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (ds *EnvironmentMembershipsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*aws.AWSCloud9Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *aws.AWSCloud9Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	ds.client = client
+}
+
+func convertMembershipToModel(membership *aws.Cloud9EnvironmentMembership) membershipModel {
+	res := membershipModel{
+		Permissions: types.StringValue(membership.Permissions),
+		UserARN:     types.StringValue(membership.UserARN),
+		UserID:      types.StringValue(membership.UserID),
+		LastAccess:  types.StringNull(),
+	}
+	if membership.LastAccess != nil {
+		res.LastAccess = types.StringValue(membership.LastAccess.UTC().Format(time.RFC3339))
+	}
+	return res
+}
+
+func (ds *EnvironmentMembershipsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data EnvironmentMembershipsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	envId := data.EnvironmentId.ValueString()
+	memberships, err := ds.client.GetMemberShips(ctx, envId)
+	if aws.IsNotFound(err) {
+		resp.Diagnostics.AddError("Environment not found", fmt.Sprintf("Unable to read the memberships of environment %s, it does not exist: %s", envId, err.Error()))
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Unable to read the memberships of environment %s: %s", envId, err.Error()))
+		return
+	}
+
+	data.Memberships = make([]membershipModel, 0, len(memberships))
+	for i := range memberships {
+		if !data.Permissions.IsNull() && data.Permissions.ValueString() != memberships[i].Permissions {
+			continue
+		}
+		if !data.UserARN.IsNull() && data.UserARN.ValueString() != memberships[i].UserARN {
+			continue
+		}
+		data.Memberships = append(data.Memberships, convertMembershipToModel(&memberships[i]))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func TestAccEnvironmentMembershipsDataSource(t *testing.T) {
+	server := testAccServer(t)
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+	server.AddMembership(envId, "arn:aws:iam::123456789012:user/reader", "read-only")
+	server.AddMembership(envId, "arn:aws:iam::123456789012:user/writer", "read-write")
+	server.SetLastAccess(envId, "arn:aws:iam::123456789012:user/writer", time.Date(2023, 7, 14, 10, 30, 0, 0, time.UTC))
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + `
+data "awscloud9_environment_memberships" "all" {
+  environment_id = "` + envId + `"
+}
+
+data "awscloud9_environment_memberships" "writers" {
+  environment_id = "` + envId + `"
+  permissions    = "read-write"
+}
+
+data "awscloud9_environment_memberships" "reader" {
+  environment_id = "` + envId + `"
+  user_arn       = "arn:aws:iam::123456789012:user/reader"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.awscloud9_environment_memberships.all", "memberships.#", "3"),
+					resource.TestCheckResourceAttr("data.awscloud9_environment_memberships.all", "memberships.0.permissions", "owner"),
+					resource.TestCheckResourceAttr("data.awscloud9_environment_memberships.all", "memberships.0.user_arn", server.CallerArn),
+					resource.TestCheckResourceAttr("data.awscloud9_environment_memberships.writers", "memberships.#", "1"),
+					resource.TestCheckResourceAttr("data.awscloud9_environment_memberships.writers", "memberships.0.user_arn", "arn:aws:iam::123456789012:user/writer"),
+					resource.TestCheckResourceAttrSet("data.awscloud9_environment_memberships.writers", "memberships.0.user_id"),
+					resource.TestCheckResourceAttr("data.awscloud9_environment_memberships.writers", "memberships.0.last_access", "2023-07-14T10:30:00Z"),
+					resource.TestCheckResourceAttr("data.awscloud9_environment_memberships.reader", "memberships.#", "1"),
+					resource.TestCheckResourceAttr("data.awscloud9_environment_memberships.reader", "memberships.0.permissions", "read-only"),
+					resource.TestCheckNoResourceAttr("data.awscloud9_environment_memberships.reader", "memberships.0.last_access"),
+				),
+			},
+		},
+	})
+}
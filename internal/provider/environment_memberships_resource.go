@@ -0,0 +1,403 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/cloud9"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
+)
+
+var (
+	_ resource.Resource                   = &EnvironmentMembershipsResource{}
+	_ resource.ResourceWithConfigure      = &EnvironmentMembershipsResource{}
+	_ resource.ResourceWithImportState    = &EnvironmentMembershipsResource{}
+	_ resource.ResourceWithValidateConfig = &EnvironmentMembershipsResource{}
+	_ resource.ResourceWithModifyPlan     = &EnvironmentMembershipsResource{}
+)
+
+type EnvironmentMembershipsResource struct {
+	client *aws.AWSCloud9Client
+}
+
+type memberModel struct {
+	Permissions types.String `tfsdk:"permissions"`
+	UserARN     types.String `tfsdk:"user_arn"`
+}
+
+type environmentMembershipsModel struct {
+	ID            types.String  `tfsdk:"id"`
+	EnvironmentId types.String  `tfsdk:"environment_id"`
+	Members       []memberModel `tfsdk:"members"`
+}
+
+func NewEnvironmentMembershipsResource() resource.Resource {
+	return &EnvironmentMembershipsResource{}
+}
+
+func (rs *EnvironmentMembershipsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_environment_memberships"
+}
+
+func (rs *EnvironmentMembershipsResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The complete set of members of a cloud9 environment. Members missing from `members` are removed from the environment, except for its owner which is never managed. Must not be used together with `awscloud9_environment_membership` on the same environment.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The id of the environment",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"environment_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the environment to manage the members of",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"members": schema.SetNestedAttribute{
+				MarkdownDescription: "The members of the environment, the owner must not be listed",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"permissions": schema.StringAttribute{
+							MarkdownDescription: "The permissions to give to the user, can be one of `read-write` and `read-only`",
+							Required:            true,
+							Validators: []validator.String{
+								validateMemberPermissions(),
+							},
+						},
+						"user_arn": schema.StringAttribute{
+							MarkdownDescription: "The arn of the aws resource that will be given membership to the environment",
+							Required:            true,
+							Validators: []validator.String{
+								validateUserArn(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (rs *EnvironmentMembershipsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*aws.AWSCloud9Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure type",
+			fmt.Sprintf("Expected *aws.AWSCloud9Client, got %T. Please report this issue to the provider developers.", req.ProviderData))
+		return
+	}
+
+	rs.client = client
+}
+
+// knownMemberArns returns the known user ARNs of the members attribute of
+// config or plan, the members are not known when the set itself is unknown.
+func knownMemberArns(ctx context.Context, members types.Set) ([]string, diag.Diagnostics) {
+	if members.IsNull() || members.IsUnknown() {
+		return nil, nil
+	}
+
+	var models []memberModel
+	diags := members.ElementsAs(ctx, &models, false)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	res := make([]string, 0, len(models))
+	for _, member := range models {
+		if member.UserARN.IsNull() || member.UserARN.IsUnknown() {
+			continue
+		}
+		res = append(res, member.UserARN.ValueString())
+	}
+	return res, diags
+}
+
+// ValidateConfig rejects the users listed more than once, which would only
+// fail once some of the memberships have been applied.
+func (rs *EnvironmentMembershipsResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var members types.Set
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("members"), &members)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	userArns, diags := knownMemberArns(ctx, members)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	seen := make(map[string]bool, len(userArns))
+	for _, userArn := range userArns {
+		if seen[userArn] {
+			resp.Diagnostics.AddAttributeError(path.Root("members"), "Duplicate member",
+				fmt.Sprintf("User %s is listed more than once, a member can only be given one permission.", userArn))
+		}
+		seen[userArn] = true
+	}
+}
+
+// ModifyPlan rejects the owner of an existing environment when it is listed
+// in the members, the owner can not be managed.
+func (rs *EnvironmentMembershipsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || rs.client == nil {
+		return
+	}
+
+	var envId types.String
+	var members types.Set
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("environment_id"), &envId)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("members"), &members)...)
+	if resp.Diagnostics.HasError() || envId.IsUnknown() {
+		return
+	}
+
+	userArns, diags := knownMemberArns(ctx, members)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || len(userArns) == 0 {
+		return
+	}
+
+	memberships, err := rs.client.GetMemberShips(ctx, envId.ValueString())
+	if aws.IsNotFound(err) {
+		// a missing environment is reported during the apply
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Could not retrieve memberships for environment %s: %s", envId.ValueString(), err.Error()))
+		return
+	}
+
+	for _, membership := range memberships {
+		if membership.Permissions != aws.OWNER {
+			continue
+		}
+		for _, userArn := range userArns {
+			if userArn == membership.UserARN {
+				resp.Diagnostics.AddAttributeError(path.Root("members"), "Owner cannot be managed",
+					fmt.Sprintf("User %s is the owner of environment %s and must not be listed in the members.", userArn, envId.ValueString()))
+			}
+		}
+	}
+}
+
+// membersToMap indexes the permissions of the members by user ARN and
+// returns an error when a user is listed twice or is the owner. The
+// configuration is checked at plan time, this only guards the apply
+// against values which were unknown then.
+func membersToMap(members []memberModel) (map[string]string, error) {
+	res := make(map[string]string, len(members))
+	for _, member := range members {
+		userArn := member.UserARN.ValueString()
+		if _, ok := res[userArn]; ok {
+			return nil, fmt.Errorf("user %s is listed more than once", userArn)
+		}
+		if member.Permissions.ValueString() == aws.OWNER {
+			return nil, fmt.Errorf("user %s cannot be given the owner permissions", userArn)
+		}
+		res[userArn] = member.Permissions.ValueString()
+	}
+	return res, nil
+}
+
+// applyMembers creates, updates and deletes memberships until the non-owner
+// members of the environment match members.
+func (rs *EnvironmentMembershipsResource) applyMembers(ctx context.Context, envId string, members map[string]string) error {
+	current, err := rs.client.GetMemberShips(ctx, envId)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]string, len(current))
+	for _, membership := range current {
+		if membership.Permissions == aws.OWNER {
+			if _, ok := members[membership.UserARN]; ok {
+				return fmt.Errorf("user %s is the owner of the environment and cannot be managed", membership.UserARN)
+			}
+			continue
+		}
+		existing[membership.UserARN] = membership.Permissions
+	}
+
+	for userArn := range existing {
+		if _, ok := members[userArn]; ok {
+			continue
+		}
+		userArn := userArn
+		_, err := rs.client.Cloud9.DeleteEnvironmentMembershipWithContext(ctx, &cloud9.DeleteEnvironmentMembershipInput{
+			EnvironmentId: &envId,
+			UserArn:       &userArn,
+		})
+		if err != nil && !aws.IsNotFound(err) {
+			return fmt.Errorf("could not remove user %s: %w", userArn, err)
+		}
+	}
+
+	for userArn, permissions := range members {
+		userArn, permissions := userArn, permissions
+		existingPermissions, ok := existing[userArn]
+
+		var err error
+		if !ok {
+			_, err = rs.client.Cloud9.CreateEnvironmentMembershipWithContext(ctx, &cloud9.CreateEnvironmentMembershipInput{
+				EnvironmentId: &envId,
+				UserArn:       &userArn,
+				Permissions:   &permissions,
+			})
+		} else if existingPermissions != permissions {
+			_, err = rs.client.Cloud9.UpdateEnvironmentMembershipWithContext(ctx, &cloud9.UpdateEnvironmentMembershipInput{
+				EnvironmentId: &envId,
+				UserArn:       &userArn,
+				Permissions:   &permissions,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("could not give %s permissions to user %s: %w", permissions, userArn, err)
+		}
+	}
+
+	return nil
+}
+
+func (rs *EnvironmentMembershipsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan environmentMembershipsModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	members, err := membersToMap(plan.Members)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid members", err.Error())
+		return
+	}
+
+	envId := plan.EnvironmentId.ValueString()
+	err = rs.applyMembers(ctx, envId, members)
+	if aws.IsNotFound(err) {
+		resp.Diagnostics.AddError("Environment not found", fmt.Sprintf("Could not manage the members of environment %s, it does not exist: %s", envId, err.Error()))
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError("Error updating memberships", fmt.Sprintf("Could not update the members of environment %s: %s", envId, err.Error()))
+		return
+	}
+
+	plan.ID = types.StringValue(envId)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (rs *EnvironmentMembershipsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state environmentMembershipsModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	envId := state.EnvironmentId.ValueString()
+	memberships, err := rs.client.GetMemberShips(ctx, envId)
+	if aws.IsNotFound(err) {
+		resp.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError("Error fetching memberships", fmt.Sprintf("Could not retrieve memberships for environment %s: %s", envId, err.Error()))
+		return
+	}
+
+	// every non-owner member is reported, so that members added outside of
+	// terraform show up as drift
+	state.Members = make([]memberModel, 0, len(memberships))
+	for _, membership := range memberships {
+		if membership.Permissions == aws.OWNER {
+			continue
+		}
+		state.Members = append(state.Members, memberModel{
+			Permissions: types.StringValue(membership.Permissions),
+			UserARN:     types.StringValue(membership.UserARN),
+		})
+	}
+	sort.Slice(state.Members, func(i, j int) bool {
+		return state.Members[i].UserARN.ValueString() < state.Members[j].UserARN.ValueString()
+	})
+	state.ID = types.StringValue(envId)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (rs *EnvironmentMembershipsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan environmentMembershipsModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	members, err := membersToMap(plan.Members)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid members", err.Error())
+		return
+	}
+
+	envId := plan.EnvironmentId.ValueString()
+	err = rs.applyMembers(ctx, envId, members)
+	if err != nil {
+		resp.Diagnostics.AddError("Error updating memberships", fmt.Sprintf("Could not update the members of environment %s: %s", envId, err.Error()))
+		return
+	}
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (rs *EnvironmentMembershipsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state environmentMembershipsModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	envId := state.EnvironmentId.ValueString()
+	err := rs.applyMembers(ctx, envId, map[string]string{})
+	if err != nil && !aws.IsNotFound(err) {
+		resp.Diagnostics.AddError("Error deleting memberships", fmt.Sprintf("Could not remove the members of environment %s: %s", envId, err.Error()))
+		return
+	}
+}
+
+func (rs *EnvironmentMembershipsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("environment_id"), req, resp)
+}
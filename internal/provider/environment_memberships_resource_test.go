@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+const (
+	testAccReaderArn = "arn:aws:iam::123456789012:user/reader"
+	testAccWriterArn = "arn:aws:iam::123456789012:user/writer"
+	testAccStrayArn  = "arn:aws:iam::123456789012:user/stray"
+)
+
+func testAccMembershipsConfig(envId, readerPermissions string) string {
+	return fmt.Sprintf(`
+resource "awscloud9_environment_memberships" "test" {
+  environment_id = %q
+
+  members = [
+    {
+      user_arn    = %q
+      permissions = %q
+    },
+    {
+      user_arn    = %q
+      permissions = "read-write"
+    },
+  ]
+}
+`, envId, testAccReaderArn, readerPermissions, testAccWriterArn)
+}
+
+func TestAccEnvironmentMembershipsResource(t *testing.T) {
+	server := testAccServer(t)
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+	server.AddMembership(envId, testAccStrayArn, "read-only")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckMembers(server, envId, map[string]string{server.CallerArn: "owner"}),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + testAccMembershipsConfig(envId, "read-only"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_environment_memberships.test", "id", envId),
+					resource.TestCheckResourceAttr("awscloud9_environment_memberships.test", "members.#", "2"),
+					testAccCheckMembers(server, envId, map[string]string{
+						server.CallerArn: "owner",
+						testAccReaderArn: "read-only",
+						testAccWriterArn: "read-write",
+					}),
+				),
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccMembershipsConfig(envId, "read-write"),
+				Check: testAccCheckMembers(server, envId, map[string]string{
+					server.CallerArn: "owner",
+					testAccReaderArn: "read-write",
+					testAccWriterArn: "read-write",
+				}),
+			},
+			{
+				PreConfig: func() {
+					server.AddMembership(envId, testAccStrayArn, "read-write")
+				},
+				Config:             testAccProviderConfig(server) + testAccMembershipsConfig(envId, "read-write"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccMembershipsConfig(envId, "read-write"),
+				Check: testAccCheckMembers(server, envId, map[string]string{
+					server.CallerArn: "owner",
+					testAccReaderArn: "read-write",
+					testAccWriterArn: "read-write",
+				}),
+			},
+			{
+				ResourceName:      "awscloud9_environment_memberships.test",
+				ImportState:       true,
+				ImportStateId:     envId,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccEnvironmentMembershipsResourceInvalidMembers(t *testing.T) {
+	server := testAccServer(t)
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com"}, nil)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + fmt.Sprintf(`
+resource "awscloud9_environment_memberships" "test" {
+  environment_id = %q
+
+  members = [
+    {
+      user_arn    = %q
+      permissions = "read-only"
+    },
+    {
+      user_arn    = %q
+      permissions = "read-write"
+    },
+  ]
+}
+`, envId, testAccReaderArn, testAccReaderArn),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Duplicate member"),
+			},
+			{
+				Config: testAccProviderConfig(server) + fmt.Sprintf(`
+resource "awscloud9_environment_memberships" "test" {
+  environment_id = %q
+
+  members = [
+    {
+      user_arn    = %q
+      permissions = "read-only"
+    },
+  ]
+}
+`, envId, server.CallerArn),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Owner cannot be managed"),
+			},
+		},
+	})
+}
+
+// testAccCheckMembers checks the memberships stored by the fake server.
+func testAccCheckMembers(server *fakecloud9.Server, envId string, expected map[string]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, userArn := range []string{server.CallerArn, testAccReaderArn, testAccWriterArn, testAccStrayArn} {
+			membership := server.Membership(envId, userArn)
+			permissions, ok := expected[userArn]
+			if !ok && membership != nil {
+				return fmt.Errorf("user %s should not be a member", userArn)
+			} else if ok && membership == nil {
+				return fmt.Errorf("user %s should be a member", userArn)
+			} else if ok && membership.Permissions != permissions {
+				return fmt.Errorf("user %s has %s permissions instead of %s", userArn, membership.Permissions, permissions)
+			}
+		}
+		return nil
+	}
+}
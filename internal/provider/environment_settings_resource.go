@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
+)
+
+var (
+	_ resource.Resource                = &EnvironmentSettingsResource{}
+	_ resource.ResourceWithConfigure   = &EnvironmentSettingsResource{}
+	_ resource.ResourceWithImportState = &EnvironmentSettingsResource{}
+)
+
+type EnvironmentSettingsResource struct {
+	client *aws.AWSCloud9Client
+}
+
+type environmentSettingsModel struct {
+	EnvironmentId types.String `tfsdk:"environment_id"`
+	Settings      types.String `tfsdk:"settings"`
+}
+
+func NewEnvironmentSettingsResource() resource.Resource {
+	return &EnvironmentSettingsResource{}
+}
+
+func (rs *EnvironmentSettingsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_environment_settings"
+}
+
+func (rs *EnvironmentSettingsResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The project settings of a cloud9 environment, such as its runners, shared by all of its members. Destroying the resource resets the settings to an empty document",
+		Attributes: map[string]schema.Attribute{
+			"environment_id": schema.StringAttribute{
+				MarkdownDescription: "The id of the environment",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"settings": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The settings, as a JSON object. Formatting and key order changes, in the configuration or made outside of terraform, are ignored",
+				Validators: []validator.String{
+					validateSettings(),
+				},
+				PlanModifiers: []planmodifier.String{
+					useEquivalentSettings(),
+				},
+			},
+		},
+	}
+}
+
+func (rs *EnvironmentSettingsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*aws.AWSCloud9Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure type",
+			fmt.Sprintf("Expected *aws.AWSCloud9Client, got %T. Please report this issue to the provider developers.", req.ProviderData))
+		return
+	}
+
+	rs.client = client
+}
+
+func (rs *EnvironmentSettingsResource) settings(environmentId types.String) settingsTarget {
+	return settingsTarget{
+		name: "environment " + environmentId.String(),
+		get: func(ctx context.Context) (string, error) {
+			return rs.client.GetEnvironmentSettings(ctx, environmentId.ValueString())
+		},
+		update: func(ctx context.Context, settings string) error {
+			return rs.client.UpdateEnvironmentSettings(ctx, environmentId.ValueString(), settings)
+		},
+	}
+}
+
+func (rs *EnvironmentSettingsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan environmentSettingsModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(rs.settings(plan.EnvironmentId).apply(ctx, plan.Settings)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (rs *EnvironmentSettingsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state environmentSettingsModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	settings, found, diags := rs.settings(state.EnvironmentId).read(ctx, state.Settings)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	} else if !found {
+		// the environment was deleted outside of terraform
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Settings = settings
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (rs *EnvironmentSettingsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state environmentSettingsModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(rs.settings(plan.EnvironmentId).change(ctx, state.Settings, plan.Settings)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags := resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (rs *EnvironmentSettingsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state environmentSettingsModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(rs.settings(state.EnvironmentId).reset(ctx)...)
+}
+
+func (rs *EnvironmentSettingsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("environment_id"), req, resp)
+}
@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func testAccEnvironmentSettingsConfig(settings string) string {
+	return fmt.Sprintf(`
+resource "awscloud9_ssh_environment" "test" {
+  name       = "env"
+  login_name = "ubuntu"
+  hostname   = "example.com"
+}
+
+resource "awscloud9_environment_settings" "test" {
+  environment_id = awscloud9_ssh_environment.test.id
+  settings       = %s
+}
+`, settings)
+}
+
+func testAccCheckEnvironmentSettings(server *fakecloud9.Server, name, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource %s not found", name)
+		}
+		env := server.Environment(rs.Primary.Attributes["environment_id"])
+		if env == nil {
+			return fmt.Errorf("environment %s not found", rs.Primary.Attributes["environment_id"])
+		}
+		if env.Settings != expected {
+			return fmt.Errorf("expected the environment settings to be %s, got %s", expected, env.Settings)
+		}
+		return nil
+	}
+}
+
+func TestAccEnvironmentSettingsResource(t *testing.T) {
+	server := testAccServer(t)
+
+	var envId string
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + testAccEnvironmentSettingsConfig(`jsonencode({ run = { configs = { main = { command = "make" } } } })`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("awscloud9_environment_settings.test", "environment_id", "awscloud9_ssh_environment.test", "id"),
+					testAccCheckEnvironmentSettings(server, "awscloud9_environment_settings.test", `{"run":{"configs":{"main":{"command":"make"}}}}`),
+					testAccCheckResourceID("awscloud9_ssh_environment.test", &envId),
+				),
+			},
+			{
+				// the settings are reformatted outside of terraform
+				PreConfig: func() {
+					server.SetEnvironmentSettings(envId, "{\n  \"run\": {\"configs\": {\"main\": {\"command\": \"make\"}}}\n}")
+				},
+				Config:   testAccProviderConfig(server) + testAccEnvironmentSettingsConfig(`jsonencode({ run = { configs = { main = { command = "make" } } } })`),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccEnvironmentSettingsConfig(`jsonencode({ run = { configs = { main = { command = "make test" } } } })`),
+				Check:  testAccCheckEnvironmentSettings(server, "awscloud9_environment_settings.test", `{"run":{"configs":{"main":{"command":"make test"}}}}`),
+			},
+			{
+				// only the formatting of the configured document changes
+				Config:   testAccProviderConfig(server) + testAccEnvironmentSettingsConfig(`"{ \"run\": { \"configs\": { \"main\": { \"command\": \"make test\" } } } }"`),
+				PlanOnly: true,
+			},
+			{
+				ResourceName:                         "awscloud9_environment_settings.test",
+				ImportState:                          true,
+				ImportStateIdFunc:                    func(s *terraform.State) (string, error) { return envId, nil },
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "environment_id",
+			},
+			{
+				// the settings are emptied outside of terraform
+				PreConfig: func() {
+					server.SetEnvironmentSettings(envId, "")
+				},
+				Config:             testAccProviderConfig(server) + testAccEnvironmentSettingsConfig(`jsonencode({ run = { configs = { main = { command = "make test" } } } })`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccEnvironmentSettingsConfig(`jsonencode({ run = { configs = { main = { command = "make test" } } } })`),
+				Check:  testAccCheckEnvironmentSettings(server, "awscloud9_environment_settings.test", `{"run":{"configs":{"main":{"command":"make test"}}}}`),
+			},
+			{
+				// the environment settings are removed along with the environment
+				PreConfig: func() {
+					server.RemoveEnvironment(envId)
+				},
+				Config:             testAccProviderConfig(server) + testAccEnvironmentSettingsConfig(`jsonencode({ run = { configs = { main = { command = "make test" } } } })`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccEnvironmentSettingsResourceInvalid(t *testing.T) {
+	server := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccProviderConfig(server) + testAccEnvironmentSettingsConfig(`"[]"`),
+				ExpectError: regexp.MustCompile("Invalid settings document"),
+			},
+		},
+	})
+}
@@ -0,0 +1,391 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/cloud9"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
+)
+
+var _ datasource.DataSource = &EnvironmentsDataSource{}
+
+func NewEnvironmentsDataSource() datasource.DataSource {
+	return &EnvironmentsDataSource{}
+}
+
+type EnvironmentsDataSource struct {
+	client *aws.AWSCloud9Client
+}
+
+type environmentsDataSourceEnvironmentModel struct {
+	Arn             types.String `tfsdk:"arn"`
+	ID              types.String `tfsdk:"id"`
+	Name            types.String `tfsdk:"name"`
+	Description     types.String `tfsdk:"description"`
+	Type            types.String `tfsdk:"type"`
+	OwnerArn        types.String `tfsdk:"owner_arn"`
+	LoginName       types.String `tfsdk:"login_name"`
+	Hostname        types.String `tfsdk:"hostname"`
+	Port            types.Int64  `tfsdk:"port"`
+	EnvironmentPath types.String `tfsdk:"environment_path"`
+	NodePath        types.String `tfsdk:"node_path"`
+	BastionURL      types.String `tfsdk:"bastion_url"`
+	Tags            types.Map    `tfsdk:"tags"`
+}
+
+type EnvironmentsDataSourceModel struct {
+	NameRegex       types.String                             `tfsdk:"name_regex"`
+	EnvironmentType types.String                             `tfsdk:"environment_type"`
+	OwnerArn        types.String                             `tfsdk:"owner_arn"`
+	Hostname        types.String                             `tfsdk:"hostname"`
+	Tags            types.Map                                `tfsdk:"tags"`
+	IDs             types.List                               `tfsdk:"ids"`
+	Environments    []environmentsDataSourceEnvironmentModel `tfsdk:"environments"`
+}
+
+func (ds *EnvironmentsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_environments"
+}
+
+func (ds *EnvironmentsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the cloud 9 environments of the account matching every given filter. Environments which could not be described are left out with a warning",
+		Attributes: map[string]schema.Attribute{
+			"name_regex": schema.StringAttribute{
+				MarkdownDescription: "A regular expression the name of the environments must match.",
+				Optional:            true,
+			},
+			"environment_type": schema.StringAttribute{
+				MarkdownDescription: "The type of the environments, either `ssh` or `ec2`.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(cloud9.EnvironmentTypeSsh, cloud9.EnvironmentTypeEc2),
+				},
+			},
+			"owner_arn": schema.StringAttribute{
+				MarkdownDescription: "The ARN of the owner of the environments.",
+				Optional:            true,
+			},
+			"hostname": schema.StringAttribute{
+				MarkdownDescription: "The hostname the SSH environments connect to.",
+				Optional:            true,
+			},
+			"tags": schema.MapAttribute{
+				MarkdownDescription: "Tags the environments must all have, with the same values.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"ids": schema.ListAttribute{
+				MarkdownDescription: "The ids of the matching environments.",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"environments": schema.ListNestedAttribute{
+				MarkdownDescription: "The matching environments.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"arn": schema.StringAttribute{
+							MarkdownDescription: "The ARN of the environment",
+							Computed:            true,
+						},
+						"id": schema.StringAttribute{
+							MarkdownDescription: "The id of the cloud 9 environment",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "The name of the environment",
+							Computed:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "The description of the environment",
+							Computed:            true,
+						},
+						"type": schema.StringAttribute{
+							MarkdownDescription: "The type of the environment, `ssh` or `ec2`",
+							Computed:            true,
+						},
+						"owner_arn": schema.StringAttribute{
+							MarkdownDescription: "The ARN of the owner of the environment",
+							Computed:            true,
+						},
+						"login_name": schema.StringAttribute{
+							MarkdownDescription: "The login name of the user bound to the environment, only set for SSH environments",
+							Computed:            true,
+						},
+						"hostname": schema.StringAttribute{
+							MarkdownDescription: "The hostname to connect to, only set for SSH environments",
+							Computed:            true,
+						},
+						"port": schema.Int64Attribute{
+							MarkdownDescription: "The port to connect to, only set for SSH environments",
+							Computed:            true,
+						},
+						"environment_path": schema.StringAttribute{
+							MarkdownDescription: "The path where the cloud 9 environment shoud open a shell into.",
+							Computed:            true,
+						},
+						"node_path": schema.StringAttribute{
+							MarkdownDescription: "The path where node is set on the remote host.",
+							Computed:            true,
+						},
+						"bastion_url": schema.StringAttribute{
+							MarkdownDescription: "The url to connect to the bastion host.",
+							Computed:            true,
+						},
+						"tags": schema.MapAttribute{
+							MarkdownDescription: "The tags of the environment.",
+							ElementType:         types.StringType,
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (ds *EnvironmentsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*aws.AWSCloud9Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *aws.AWSCloud9Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	ds.client = client
+}
+
+func convertEnvironmentToListModel(environment *aws.Cloud9SSHEnvironment) (environmentsDataSourceEnvironmentModel, diag.Diagnostics) {
+	var state SSHEnvironmentModel
+	diags := convertModelToPlan(&state, environment)
+
+	res := environmentsDataSourceEnvironmentModel{
+		Arn:             state.Arn,
+		ID:              state.ID,
+		Name:            state.Name,
+		Description:     state.Description,
+		Type:            types.StringValue(environment.Type),
+		OwnerArn:        types.StringValue(environment.OwnerArn),
+		LoginName:       state.LoginName,
+		Hostname:        state.Hostname,
+		Port:            state.Port,
+		EnvironmentPath: state.EnvironmentPath,
+		NodePath:        state.NodePath,
+		BastionURL:      state.BastionURL,
+		Tags:            state.Tags,
+	}
+	if environment.Type != cloud9.EnvironmentTypeSsh {
+		res.LoginName = types.StringNull()
+		res.Hostname = types.StringNull()
+		res.Port = types.Int64Null()
+		res.EnvironmentPath = types.StringNull()
+		res.NodePath = types.StringNull()
+	}
+
+	return res, diags
+}
+
+// environmentFilter selects environments, empty fields match every
+// environment.
+type environmentFilter struct {
+	Name            string
+	NameRegex       *regexp.Regexp
+	EnvironmentType string
+	OwnerArn        string
+	Hostname        string
+	Tags            map[string]string
+}
+
+// matchDescribed only checks the fields returned by DescribeEnvironments,
+// so that the environments can be filtered before being hydrated.
+func (filter *environmentFilter) matchDescribed(environment *aws.Cloud9SSHEnvironment) bool {
+	if len(filter.Name) > 0 && filter.Name != environment.Name {
+		return false
+	}
+	if filter.NameRegex != nil && !filter.NameRegex.MatchString(environment.Name) {
+		return false
+	}
+	if len(filter.EnvironmentType) > 0 && filter.EnvironmentType != environment.Type {
+		return false
+	}
+	if len(filter.OwnerArn) > 0 && filter.OwnerArn != environment.OwnerArn {
+		return false
+	}
+	return true
+}
+
+func (filter *environmentFilter) match(environment *aws.Cloud9SSHEnvironment) bool {
+	if !filter.matchDescribed(environment) {
+		return false
+	}
+	if len(filter.Hostname) > 0 && filter.Hostname != environment.Hostname {
+		return false
+	}
+
+	environmentTags := make(map[string]string)
+	for _, tag := range environment.Tags {
+		environmentTags[tag.Key] = tag.Value
+	}
+	for key, value := range filter.Tags {
+		if environmentValue, ok := environmentTags[key]; !ok || environmentValue != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String describes the filter in diagnostics.
+func (filter *environmentFilter) String() string {
+	var parts []string
+	if len(filter.Name) > 0 {
+		parts = append(parts, fmt.Sprintf("name %q", filter.Name))
+	}
+	if filter.NameRegex != nil {
+		parts = append(parts, fmt.Sprintf("name_regex %q", filter.NameRegex.String()))
+	}
+	if len(filter.EnvironmentType) > 0 {
+		parts = append(parts, fmt.Sprintf("type %q", filter.EnvironmentType))
+	}
+	if len(filter.OwnerArn) > 0 {
+		parts = append(parts, fmt.Sprintf("owner_arn %q", filter.OwnerArn))
+	}
+	if len(filter.Hostname) > 0 {
+		parts = append(parts, fmt.Sprintf("hostname %q", filter.Hostname))
+	}
+
+	keys := make([]string, 0, len(filter.Tags))
+	for key := range filter.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("tag %s=%q", key, filter.Tags[key]))
+	}
+
+	if len(parts) == 0 {
+		return "no filter"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// findEnvironments returns the environments of the account matching the
+// filter. Only the environments matching the described fields are hydrated
+// to check their hostname and tags. The matching environments are also
+// returned along with a *aws.PartialResultsError when some could not be
+// described.
+func findEnvironments(ctx context.Context, client *aws.AWSCloud9Client, filter *environmentFilter) ([]aws.Cloud9SSHEnvironment, error) {
+	envIds, err := client.ListEnvironments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	described, err := client.DescribeEnvironments(ctx, envIds...)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]aws.Cloud9SSHEnvironment, 0, len(described))
+	for i := range described {
+		if filter.matchDescribed(&described[i]) {
+			candidates = append(candidates, described[i])
+		}
+	}
+
+	environments, err := client.HydrateSSHEnvironments(ctx, candidates)
+	if _, ok := aws.AsPartialResultsError(err); err != nil && !ok {
+		return nil, err
+	}
+
+	res := make([]aws.Cloud9SSHEnvironment, 0, len(environments))
+	for i := range environments {
+		if filter.match(&environments[i]) {
+			res = append(res, environments[i])
+		}
+	}
+	return res, err
+}
+
+func (ds *EnvironmentsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data EnvironmentsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filter := environmentFilter{
+		EnvironmentType: data.EnvironmentType.ValueString(),
+		OwnerArn:        data.OwnerArn.ValueString(),
+		Hostname:        data.Hostname.ValueString(),
+		Tags:            make(map[string]string),
+	}
+	if !data.NameRegex.IsNull() {
+		var err error
+		filter.NameRegex, err = regexp.Compile(data.NameRegex.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid name_regex", fmt.Sprintf("Could not compile %s: %s", data.NameRegex.String(), err.Error()))
+			return
+		}
+	}
+	if !data.Tags.IsNull() {
+		resp.Diagnostics.Append(data.Tags.ElementsAs(ctx, &filter.Tags, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	environments, err := findEnvironments(ctx, ds.client, &filter)
+	if partialErr, ok := aws.AsPartialResultsError(err); ok {
+		// the environments which could be described are still returned
+		for _, envErr := range partialErr.Errors {
+			resp.Diagnostics.AddWarning("Environment skipped", fmt.Sprintf("Unable to describe environment %s, it is left out of the results: %s", envErr.EnvironmentId, envErr.Err.Error()))
+		}
+	} else if err != nil {
+		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Unable to list environments: %s", err.Error()))
+		return
+	}
+
+	ids := make([]string, 0, len(environments))
+	data.Environments = make([]environmentsDataSourceEnvironmentModel, 0, len(environments))
+	for i := range environments {
+		environment, diags := convertEnvironmentToListModel(&environments[i])
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		ids = append(ids, environments[i].EnvironmentId)
+		data.Environments = append(data.Environments, environment)
+	}
+
+	var diags diag.Diagnostics
+	data.IDs, diags = types.ListValueFrom(ctx, types.StringType, ids)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func TestAccEnvironmentsDataSource(t *testing.T) {
+	server := testAccServer(t)
+	infraId := server.AddSSHEnvironment("infra-dev", fakecloud9.SSHRemote{
+		LoginName: "ubuntu",
+		Hostname:  "dev.example.com",
+		Port:      22,
+	}, map[string]string{"team": "infra", "stage": "dev"})
+	server.AddSSHEnvironment("infra-prod", fakecloud9.SSHRemote{
+		LoginName: "ubuntu",
+		Hostname:  "prod.example.com",
+		Port:      22,
+	}, map[string]string{"team": "infra", "stage": "prod"})
+	server.AddSSHEnvironment("data", fakecloud9.SSHRemote{
+		LoginName: "ubuntu",
+		Hostname:  "dev.example.com",
+		Port:      22,
+	}, map[string]string{"team": "data"})
+	ec2Id := server.AddEC2Environment("infra-ec2", map[string]string{"team": "infra"})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + `
+data "awscloud9_environments" "all" {}
+
+data "awscloud9_environments" "infra_ssh" {
+  name_regex       = "^infra-"
+  environment_type = "ssh"
+}
+
+data "awscloud9_environments" "dev" {
+  hostname = "dev.example.com"
+
+  tags = {
+    team = "infra"
+  }
+}
+
+data "awscloud9_environments" "ec2" {
+  environment_type = "ec2"
+  owner_arn        = "` + server.CallerArn + `"
+}
+
+data "awscloud9_environments" "none" {
+  name_regex = "^missing$"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.awscloud9_environments.all", "ids.#", "4"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.all", "environments.#", "4"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.infra_ssh", "ids.#", "2"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.dev", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.dev", "ids.0", infraId),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.dev", "environments.0.name", "infra-dev"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.dev", "environments.0.type", "ssh"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.dev", "environments.0.login_name", "ubuntu"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.dev", "environments.0.port", "22"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.dev", "environments.0.tags.stage", "dev"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.ec2", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.ec2", "ids.0", ec2Id),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.ec2", "environments.0.owner_arn", server.CallerArn),
+					resource.TestCheckNoResourceAttr("data.awscloud9_environments.ec2", "environments.0.hostname"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.none", "ids.#", "0"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(server) + `
+data "awscloud9_environments" "invalid" {
+  name_regex = "("
+}
+`,
+				ExpectError: regexp.MustCompile("Invalid name_regex"),
+			},
+		},
+	})
+}
+
+func TestAccEnvironmentsDataSourcePartialResults(t *testing.T) {
+	server := testAccServer(t)
+	for _, name := range []string{"env-0", "env-1"} {
+		server.AddSSHEnvironment(name, fakecloud9.SSHRemote{
+			LoginName: "ubuntu",
+			Hostname:  "example.com",
+		}, nil)
+	}
+	ec2Id := server.AddEC2Environment("ec2", nil)
+
+	// the SSH environments can never be described, ec2 environments have no
+	// remote to describe
+	server.InjectError("DescribeSSHRemote", &fakecloud9.Error{
+		Status:        http.StatusForbidden,
+		ExceptionType: "AccessDeniedException",
+	}, 100)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + `
+data "awscloud9_environments" "all" {}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.awscloud9_environments.all", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.all", "ids.0", ec2Id),
+				),
+			},
+		},
+	})
+}
+
+func TestAccEnvironmentsDataSourceFiltersBeforeHydration(t *testing.T) {
+	server := testAccServer(t)
+	for _, name := range []string{"env-0", "env-1"} {
+		server.AddSSHEnvironment(name, fakecloud9.SSHRemote{
+			LoginName: "ubuntu",
+			Hostname:  "example.com",
+		}, nil)
+	}
+	ec2Id := server.AddEC2Environment("ec2", nil)
+
+	// the SSH environments are filtered out on their type, so their remote
+	// is never described
+	server.InjectError("DescribeSSHRemote", &fakecloud9.Error{
+		Status:        http.StatusForbidden,
+		ExceptionType: "AccessDeniedException",
+	}, 100)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + `
+data "awscloud9_environments" "ec2" {
+  environment_type = "ec2"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.awscloud9_environments.ec2", "ids.#", "1"),
+					resource.TestCheckResourceAttr("data.awscloud9_environments.ec2", "ids.0", ec2Id),
+					func(s *terraform.State) error {
+						if calls := server.Calls("DescribeSSHRemote"); calls != 0 {
+							return fmt.Errorf("expected no DescribeSSHRemote call, got %d", calls)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
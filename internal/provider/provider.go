@@ -2,10 +2,11 @@ package provider
 
 import (
 	"context"
-	"os"
+	"fmt"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -20,9 +21,51 @@ type AWSCloud9Provider struct {
 }
 
 type AWSCloud9ProviderModel struct {
-	AccessKeyID     types.String `tfsdk:"aws_access_key_id"`
-	SecretAccessKey types.String `tfsdk:"aws_secret_access_key"`
-	Region          types.String `tfsdk:"region"`
+	AccessKeyID            types.String      `tfsdk:"aws_access_key_id"`
+	SecretAccessKey        types.String      `tfsdk:"aws_secret_access_key"`
+	SessionToken           types.String      `tfsdk:"aws_session_token"`
+	Region                 types.String      `tfsdk:"region"`
+	MaxRetries             types.Int64       `tfsdk:"max_retries"`
+	MaxConcurrency         types.Int64       `tfsdk:"max_concurrency"`
+	MaxRequestsPerSecond   types.Int64       `tfsdk:"max_requests_per_second"`
+	CacheReads             types.Bool        `tfsdk:"cache_reads"`
+	LogRequestBodies       types.Bool        `tfsdk:"log_request_bodies"`
+	Profile                types.String      `tfsdk:"profile"`
+	SharedConfigFiles      types.List        `tfsdk:"shared_config_files"`
+	SharedCredentialsFiles types.List        `tfsdk:"shared_credentials_files"`
+	AssumeRole             *assumeRoleModel  `tfsdk:"assume_role"`
+	WebIdentity            *webIdentityModel `tfsdk:"assume_role_with_web_identity"`
+	Endpoints              *endpointsModel   `tfsdk:"endpoints"`
+	DefaultTags            *defaultTagsModel `tfsdk:"default_tags"`
+	IgnoreTags             *ignoreTagsModel  `tfsdk:"ignore_tags"`
+}
+
+type defaultTagsModel struct {
+	Tags types.Map `tfsdk:"tags"`
+}
+
+type ignoreTagsModel struct {
+	Keys        types.List `tfsdk:"keys"`
+	KeyPrefixes types.List `tfsdk:"key_prefixes"`
+}
+
+type assumeRoleModel struct {
+	RoleARN     types.String `tfsdk:"role_arn"`
+	SessionName types.String `tfsdk:"session_name"`
+	ExternalID  types.String `tfsdk:"external_id"`
+	Duration    types.String `tfsdk:"duration"`
+}
+
+type endpointsModel struct {
+	Cloud9 types.String `tfsdk:"cloud9"`
+	STS    types.String `tfsdk:"sts"`
+}
+
+type webIdentityModel struct {
+	RoleARN              types.String `tfsdk:"role_arn"`
+	SessionName          types.String `tfsdk:"session_name"`
+	WebIdentityToken     types.String `tfsdk:"web_identity_token"`
+	WebIdentityTokenFile types.String `tfsdk:"web_identity_token_file"`
 }
 
 func New(version string) func() provider.Provider {
@@ -48,12 +91,135 @@ func (p *AWSCloud9Provider) Schema(ctx context.Context, req provider.SchemaReque
 			"aws_secret_access_key": schema.StringAttribute{
 				MarkdownDescription: "The AWS Secret access key, if not provided, extracted from `AWS_SECRET_ACCESS_KEY` env variable.",
 				Optional:            true,
+				Sensitive:           true,
+			},
+			"aws_session_token": schema.StringAttribute{
+				MarkdownDescription: "The AWS session token for temporary credentials, if not provided, extracted from `AWS_SESSION_TOKEN` env variable.",
+				Optional:            true,
+				Sensitive:           true,
 			},
 			"region": schema.StringAttribute{
-				MarkdownDescription: "The AWS region to use the provider for, if not provided, extracted from `AWS_REGION` env variable.",
+				MarkdownDescription: "The AWS region to use the provider for, if not provided, extracted from `AWS_REGION` env variable or from the shared config profile.",
+				Optional:            true,
+			},
+			"max_retries": schema.Int64Attribute{
+				MarkdownDescription: "The maximum number of retries of throttled or failed calls to the AWS APIs, defaults to 10.",
+				Optional:            true,
+			},
+			"max_concurrency": schema.Int64Attribute{
+				MarkdownDescription: "The maximum number of environments described at once when looking several environments up, defaults to 8.",
+				Optional:            true,
+			},
+			"max_requests_per_second": schema.Int64Attribute{
+				MarkdownDescription: "The maximum number of calls per second made to describe environments when looking several environments up, defaults to 20.",
+				Optional:            true,
+			},
+			"cache_reads": schema.BoolAttribute{
+				MarkdownDescription: "Cache the results of the read calls for the duration of a terraform command, so that resources sharing an environment describe it once. Changes made by the provider invalidate the cache, changes made outside of it during the command are not seen. Defaults to false.",
+				Optional:            true,
+			},
+			"log_request_bodies": schema.BoolAttribute{
+				MarkdownDescription: "Log the request and response bodies of the cloud9 calls at TRACE level, with their secrets redacted. The calls are always logged at DEBUG level without their bodies, see `TF_LOG_PROVIDER_AWSCLOUD9_CLOUD9`. Defaults to false.",
+				Optional:            true,
+			},
+			"profile": schema.StringAttribute{
+				MarkdownDescription: "The profile of the shared config and credentials files to use, if not provided, extracted from `AWS_PROFILE` env variable.",
+				Optional:            true,
+			},
+			"shared_config_files": schema.ListAttribute{
+				MarkdownDescription: "The paths of the shared config files, defaults to `~/.aws/config`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+			"shared_credentials_files": schema.ListAttribute{
+				MarkdownDescription: "The paths of the shared credentials files, defaults to `~/.aws/credentials`.",
+				ElementType:         types.StringType,
 				Optional:            true,
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"endpoints": schema.SingleNestedBlock{
+				MarkdownDescription: "Custom service endpoints, such as FIPS or VPC interface endpoints, emulators or test servers.",
+				Attributes: map[string]schema.Attribute{
+					"cloud9": schema.StringAttribute{
+						MarkdownDescription: "The endpoint of the cloud9 API.",
+						Optional:            true,
+					},
+					"sts": schema.StringAttribute{
+						MarkdownDescription: "The endpoint of the STS API, used to assume roles.",
+						Optional:            true,
+					},
+				},
+			},
+			"assume_role": schema.SingleNestedBlock{
+				MarkdownDescription: "A role to assume with the resolved credentials.",
+				Attributes: map[string]schema.Attribute{
+					"role_arn": schema.StringAttribute{
+						MarkdownDescription: "The ARN of the role to assume.",
+						Optional:            true,
+					},
+					"session_name": schema.StringAttribute{
+						MarkdownDescription: "The name of the session of the assumed role.",
+						Optional:            true,
+					},
+					"external_id": schema.StringAttribute{
+						MarkdownDescription: "The external id to pass when assuming the role.",
+						Optional:            true,
+					},
+					"duration": schema.StringAttribute{
+						MarkdownDescription: "The duration of the session, formatted like `1h30m`.",
+						Optional:            true,
+					},
+				},
+			},
+			"default_tags": schema.SingleNestedBlock{
+				MarkdownDescription: "Tags added to every taggable resource, the tags of a resource take precedence.",
+				Attributes: map[string]schema.Attribute{
+					"tags": schema.MapAttribute{
+						MarkdownDescription: "The tags to add to every resource.",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+				},
+			},
+			"ignore_tags": schema.SingleNestedBlock{
+				MarkdownDescription: "Tags which are neither read nor modified by the provider, such as tags managed by other tools, they cannot be set in the `tags` of a resource. Tags prefixed by `aws:` are always ignored.",
+				Attributes: map[string]schema.Attribute{
+					"keys": schema.ListAttribute{
+						MarkdownDescription: "The keys of the tags to ignore.",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+					"key_prefixes": schema.ListAttribute{
+						MarkdownDescription: "The prefixes of the keys of the tags to ignore.",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+				},
+			},
+			"assume_role_with_web_identity": schema.SingleNestedBlock{
+				MarkdownDescription: "A role to assume with a web identity token, such as an OIDC token from a CI system.",
+				Attributes: map[string]schema.Attribute{
+					"role_arn": schema.StringAttribute{
+						MarkdownDescription: "The ARN of the role to assume.",
+						Optional:            true,
+					},
+					"session_name": schema.StringAttribute{
+						MarkdownDescription: "The name of the session of the assumed role.",
+						Optional:            true,
+					},
+					"web_identity_token": schema.StringAttribute{
+						MarkdownDescription: "The web identity token.",
+						Optional:            true,
+						Sensitive:           true,
+					},
+					"web_identity_token_file": schema.StringAttribute{
+						MarkdownDescription: "The path of a file containing the web identity token.",
+						Optional:            true,
+					},
+				},
+			},
+		},
 	}
 }
 
@@ -66,40 +232,104 @@ func (p *AWSCloud9Provider) Configure(ctx context.Context, req provider.Configur
 		return
 	}
 
-	if data.AccessKeyID.IsNull() {
-		value := os.Getenv("AWS_ACCESS_KEY_ID")
-		if len(value) == 0 {
-			resp.Diagnostics.AddError("Missing credential", "Missing AWS access key id")
+	config := aws.Config{
+		AccessKeyID:     data.AccessKeyID.ValueString(),
+		SecretAccessKey: data.SecretAccessKey.ValueString(),
+		SessionToken:    data.SessionToken.ValueString(),
+		Region:          data.Region.ValueString(),
+		Profile:         data.Profile.ValueString(),
+	}
+
+	resp.Diagnostics.Append(data.SharedConfigFiles.ElementsAs(ctx, &config.SharedConfigFiles, false)...)
+	resp.Diagnostics.Append(data.SharedCredentialsFiles.ElementsAs(ctx, &config.SharedCredentialsFiles, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.AssumeRole != nil {
+		if data.AssumeRole.RoleARN.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("assume_role").AtName("role_arn"), "Missing role ARN", "A role ARN is required to assume a role")
 			return
-		} else {
-			data.AccessKeyID = types.StringValue(value)
+		}
+		config.AssumeRole = &aws.AssumeRoleConfig{
+			RoleARN:     data.AssumeRole.RoleARN.ValueString(),
+			SessionName: data.AssumeRole.SessionName.ValueString(),
+			ExternalID:  data.AssumeRole.ExternalID.ValueString(),
+		}
+		if !data.AssumeRole.Duration.IsNull() {
+			duration, err := time.ParseDuration(data.AssumeRole.Duration.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("assume_role").AtName("duration"), "Invalid duration", fmt.Sprintf("Could not parse duration %s: %s", data.AssumeRole.Duration.String(), err.Error()))
+				return
+			}
+			config.AssumeRole.Duration = duration
 		}
 	}
 
-	if data.SecretAccessKey.IsNull() {
-		value := os.Getenv("AWS_SECRET_ACCESS_KEY")
-		if len(value) == 0 {
-			resp.Diagnostics.AddError("Missing credential", "Missing AWS secret access key")
+	if data.WebIdentity != nil {
+		if data.WebIdentity.RoleARN.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("assume_role_with_web_identity").AtName("role_arn"), "Missing role ARN", "A role ARN is required to assume a role")
 			return
-		} else {
-			data.SecretAccessKey = types.StringValue(value)
+		}
+		config.WebIdentity = &aws.WebIdentityConfig{
+			RoleARN:              data.WebIdentity.RoleARN.ValueString(),
+			SessionName:          data.WebIdentity.SessionName.ValueString(),
+			WebIdentityToken:     data.WebIdentity.WebIdentityToken.ValueString(),
+			WebIdentityTokenFile: data.WebIdentity.WebIdentityTokenFile.ValueString(),
+		}
+	}
+
+	if data.Endpoints != nil {
+		config.Endpoints = aws.Endpoints{
+			Cloud9: data.Endpoints.Cloud9.ValueString(),
+			STS:    data.Endpoints.STS.ValueString(),
 		}
 	}
 
-	if data.Region.IsNull() {
-		value := os.Getenv("AWS_REGION")
-		if len(value) == 0 {
-			resp.Diagnostics.AddError("Missing aws region", "Missing AWS region configuration")
+	sess, err := aws.NewSession(ctx, &config)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid AWS configuration", fmt.Sprintf("Could not configure the AWS session: %s", err.Error()))
+		return
+	}
+
+	options := aws.DefaultClientOptions()
+	options.Endpoint = config.Endpoints.Cloud9
+	if !data.MaxRetries.IsNull() {
+		if data.MaxRetries.ValueInt64() < 0 {
+			resp.Diagnostics.AddAttributeError(path.Root("max_retries"), "Invalid max retries", "The maximum number of retries must be positive")
+			return
+		}
+		options.MaxRetries = int(data.MaxRetries.ValueInt64())
+	}
+	if !data.MaxConcurrency.IsNull() {
+		if data.MaxConcurrency.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(path.Root("max_concurrency"), "Invalid max concurrency", "The maximum concurrency must be at least 1")
 			return
-		} else {
-			data.Region = types.StringValue(value)
 		}
+		options.Concurrency = int(data.MaxConcurrency.ValueInt64())
+	}
+	if !data.MaxRequestsPerSecond.IsNull() {
+		if data.MaxRequestsPerSecond.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(path.Root("max_requests_per_second"), "Invalid max requests per second", "The maximum number of requests per second must be at least 1")
+			return
+		}
+		options.RequestsPerSecond = int(data.MaxRequestsPerSecond.ValueInt64())
+	}
+	options.CacheReads = data.CacheReads.ValueBool()
+	options.LogBodies = data.LogRequestBodies.ValueBool()
+
+	if data.DefaultTags != nil {
+		resp.Diagnostics.Append(data.DefaultTags.Tags.ElementsAs(ctx, &options.Tags.DefaultTags, false)...)
+	}
+	if data.IgnoreTags != nil {
+		resp.Diagnostics.Append(data.IgnoreTags.Keys.ElementsAs(ctx, &options.Tags.IgnoreTags.Keys, false)...)
+		resp.Diagnostics.Append(data.IgnoreTags.KeyPrefixes.ElementsAs(ctx, &options.Tags.IgnoreTags.KeyPrefixes, false)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	client := aws.New(ctx, credentials.NewStaticCredentials(
-		data.AccessKeyID.ValueString(), data.SecretAccessKey.ValueString(), ""),
-		data.Region.ValueString(),
-	)
+	client := aws.New(sess, options)
 	resp.DataSourceData = client
 	resp.ResourceData = client
 }
@@ -107,12 +337,19 @@ func (p *AWSCloud9Provider) Configure(ctx context.Context, req provider.Configur
 func (p *AWSCloud9Provider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewSSHEnvironmentDataSource,
+		NewEnvironmentsDataSource,
+		NewEnvironmentMembershipsDataSource,
+		NewUserPublicKeyDataSource,
 	}
 }
 
 func (p *AWSCloud9Provider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewSSHEnvironmentResource,
+		NewEC2EnvironmentResource,
 		NewEnvironmentMembershipResource,
+		NewEnvironmentMembershipsResource,
+		NewUserSettingsResource,
+		NewEnvironmentSettingsResource,
 	}
 }
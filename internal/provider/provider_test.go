@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+// testAccProtoV6ProviderFactories are used to instantiate a provider during
+// acceptance testing.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"awscloud9": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// testAccServer starts a fake cloud9 server for the duration of the test.
+func testAccServer(t *testing.T) *fakecloud9.Server {
+	server := fakecloud9.NewServer()
+	t.Cleanup(server.Close)
+
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	return server
+}
+
+// testAccProviderConfig points the provider to the fake server with static
+// credentials.
+func testAccProviderConfig(server *fakecloud9.Server) string {
+	return testAccProviderConfigWithBlocks(server, "")
+}
+
+// testAccProviderConfigWithBlocks adds blocks, such as default_tags, to the
+// provider configuration.
+func testAccProviderConfigWithBlocks(server *fakecloud9.Server, blocks string) string {
+	return fmt.Sprintf(`
+provider "awscloud9" {
+  aws_access_key_id     = "AKIDTEST"
+  aws_secret_access_key = "secret"
+  region                = %q
+
+  # the rate limiting would only slow the tests down
+  max_requests_per_second = 1000
+
+  endpoints {
+    cloud9 = %q
+  }
+%s}
+`, server.Region, server.URL, blocks)
+}
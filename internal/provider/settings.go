@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
+)
+
+// EMPTY_SETTINGS is the document the settings are reset to on destroy.
+const EMPTY_SETTINGS = "{}"
+
+// normalizeSettings returns the compact form of a settings document, with
+// sorted keys. Numbers are kept as written.
+func normalizeSettings(settings string) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(settings)))
+	decoder.UseNumber()
+
+	var document map[string]interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return "", err
+	}
+	if document == nil {
+		return "", fmt.Errorf("the document must be a JSON object")
+	}
+	if decoder.More() {
+		return "", fmt.Errorf("unexpected data after the JSON object")
+	}
+
+	res, err := json.Marshal(document)
+	if err != nil {
+		return "", err
+	}
+	return string(res), nil
+}
+
+// settingsEqual reports whether two settings documents only differ by
+// formatting or key order.
+func settingsEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	normalizedA, err := normalizeSettings(a)
+	if err != nil {
+		return false
+	}
+	normalizedB, err := normalizeSettings(b)
+	if err != nil {
+		return false
+	}
+	return normalizedA == normalizedB
+}
+
+// readSettings returns the settings attribute from the remote document, the
+// current value is kept when equivalent so that formatting is not reported
+// as drift. The API returns an empty string for settings never set, which
+// is read as EMPTY_SETTINGS.
+func readSettings(current types.String, remote string) (types.String, error) {
+	if len(remote) == 0 {
+		remote = EMPTY_SETTINGS
+	}
+	if !current.IsNull() && !current.IsUnknown() && settingsEqual(current.ValueString(), remote) {
+		return current, nil
+	}
+	normalized, err := normalizeSettings(remote)
+	if err != nil {
+		return types.StringNull(), err
+	}
+	return types.StringValue(normalized), nil
+}
+
+// settingsTarget reads and writes a settings document, either the settings
+// of the user or the ones of an environment.
+type settingsTarget struct {
+	// name describes the settings in the diagnostics, such as "the user"
+	name   string
+	get    func(ctx context.Context) (string, error)
+	update func(ctx context.Context, settings string) error
+}
+
+// apply sends the normalized settings.
+func (t settingsTarget) apply(ctx context.Context, settings types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	normalized, err := normalizeSettings(settings.ValueString())
+	if err != nil {
+		diags.AddError("Invalid settings document", err.Error())
+		return diags
+	}
+
+	err = t.update(ctx, normalized)
+	if err != nil {
+		diags.AddError("Error updating settings", fmt.Sprintf("Could not update the settings of %s: %s", t.name, err.Error()))
+	}
+	return diags
+}
+
+// read returns the settings attribute from the remote document, see
+// readSettings. The settings are not found when their environment is not.
+func (t settingsTarget) read(ctx context.Context, current types.String) (types.String, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	remote, err := t.get(ctx)
+	if aws.IsNotFound(err) {
+		return types.StringNull(), false, diags
+	} else if err != nil {
+		diags.AddError("Error fetching settings", fmt.Sprintf("Could not retrieve the settings of %s: %s", t.name, err.Error()))
+		return types.StringNull(), true, diags
+	}
+
+	settings, err := readSettings(current, remote)
+	if err != nil {
+		diags.AddError("Invalid settings document", fmt.Sprintf("The settings of %s are not a JSON object: %s", t.name, err.Error()))
+	}
+	return settings, true, diags
+}
+
+// change applies the planned settings, unless they are equivalent to the
+// ones of the state.
+func (t settingsTarget) change(ctx context.Context, state, plan types.String) diag.Diagnostics {
+	if settingsEqual(state.ValueString(), plan.ValueString()) {
+		return nil
+	}
+	return t.apply(ctx, plan)
+}
+
+// reset empties the settings, they are already gone along with their
+// environment.
+func (t settingsTarget) reset(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	err := t.update(ctx, EMPTY_SETTINGS)
+	if err != nil && !aws.IsNotFound(err) {
+		diags.AddError("Error resetting settings", fmt.Sprintf("Could not reset the settings of %s: %s", t.name, err.Error()))
+	}
+	return diags
+}
+
+var _ validator.String = settingsValidator{}
+
+type settingsValidator struct{}
+
+func (v settingsValidator) Description(ctx context.Context) string {
+	return "value must be a JSON object"
+}
+
+func (v settingsValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v settingsValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := normalizeSettings(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid settings document",
+			fmt.Sprintf("The settings are not a valid document, %s: %s.", v.Description(ctx), err.Error()))
+	}
+}
+
+// validateSettings checks that a string is a JSON object, use jsonencode to
+// build it.
+func validateSettings() validator.String {
+	return settingsValidator{}
+}
+
+var _ planmodifier.String = settingsPlanModifier{}
+
+type settingsPlanModifier struct{}
+
+func (m settingsPlanModifier) Description(ctx context.Context) string {
+	return "keeps the settings of the state when the configured document only differs by formatting or key order"
+}
+
+func (m settingsPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m settingsPlanModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if req.StateValue.IsNull() || req.PlanValue.IsNull() || req.PlanValue.IsUnknown() {
+		return
+	}
+
+	if settingsEqual(req.StateValue.ValueString(), req.PlanValue.ValueString()) {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// useEquivalentSettings plans the settings of the state when the configured
+// document is equivalent, so that reformatting it plans no update.
+func useEquivalentSettings() planmodifier.String {
+	return settingsPlanModifier{}
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestNormalizeSettings(t *testing.T) {
+	documents := map[string]string{
+		`{"b": 1, "a": {"d": [1, 2], "c": true}}`: `{"a":{"c":true,"d":[1,2]},"b":1}`,
+		`{"size": 12345678901234567890}`:          `{"size":12345678901234567890}`,
+		`{"ratio": 1.50}`:                         `{"ratio":1.50}`,
+		"{}":                                      "{}",
+		"[]":                                      "",
+		"null":                                    "",
+		`"settings"`:                              "",
+		`{"a": 1} {"b": 2}`:                       "",
+		`{"a": 1`:                                 "",
+	}
+
+	for document, expected := range documents {
+		normalized, err := normalizeSettings(document)
+		if len(expected) == 0 {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %s", document, normalized)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", document, err)
+		} else if normalized != expected {
+			t.Errorf("%q: expected %s, got %s", document, expected, normalized)
+		}
+	}
+}
+
+func TestReadSettings(t *testing.T) {
+	current := types.StringValue("{\n  \"b\": 1,\n  \"a\": 2\n}")
+
+	read, err := readSettings(current, `{"a":2,"b":1}`)
+	if err != nil || !read.Equal(current) {
+		t.Errorf("expected the equivalent document to be kept, got %s %v", read, err)
+	}
+
+	read, err = readSettings(current, `{"b":1, "a":3}`)
+	if err != nil || read.ValueString() != `{"a":3,"b":1}` {
+		t.Errorf("expected the normalized remote document, got %s %v", read, err)
+	}
+
+	read, err = readSettings(types.StringNull(), `{"b":1, "a":2}`)
+	if err != nil || read.ValueString() != `{"a":2,"b":1}` {
+		t.Errorf("expected the normalized remote document, got %s %v", read, err)
+	}
+
+	read, err = readSettings(types.StringNull(), "")
+	if err != nil || read.ValueString() != EMPTY_SETTINGS {
+		t.Errorf("expected the empty remote document to be read as %s, got %s %v", EMPTY_SETTINGS, read, err)
+	}
+
+	read, err = readSettings(types.StringValue("{ }"), "")
+	if err != nil || read.ValueString() != "{ }" {
+		t.Errorf("expected the equivalent document to be kept, got %s %v", read, err)
+	}
+}
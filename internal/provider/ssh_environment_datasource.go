@@ -3,9 +3,14 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/service/cloud9"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
 )
@@ -20,13 +25,23 @@ type SSHEnvironmentDataSource struct {
 	client *aws.AWSCloud9Client
 }
 
-type membershipModel struct {
-	Permissions types.String `tfsdk:"permissions"`
-	UserARN     types.String `tfsdk:"user_arn"`
+// SSHEnvironmentDataSourceModel keeps the tags filter apart from the tags
+// of the environment.
+type SSHEnvironmentDataSourceModel struct {
+	Arn             types.String `tfsdk:"arn"`
+	ID              types.String `tfsdk:"id"`
+	Name            types.String `tfsdk:"name"`
+	Description     types.String `tfsdk:"description"`
+	LoginName       types.String `tfsdk:"login_name"`
+	Hostname        types.String `tfsdk:"hostname"`
+	Port            types.Int64  `tfsdk:"port"`
+	EnvironmentPath types.String `tfsdk:"environment_path"`
+	NodePath        types.String `tfsdk:"node_path"`
+	BastionURL      types.String `tfsdk:"bastion_url"`
+	FilterTags      types.Map    `tfsdk:"filter_tags"`
+	Tags            types.Map    `tfsdk:"tags"`
 }
 
-type SSHEnvironmentDataSourceModel = SSHEnvironmentModel
-
 func (ds *SSHEnvironmentDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_ssh_environment"
 }
@@ -42,13 +57,17 @@ func (ds *SSHEnvironmentDataSource) Schema(ctx context.Context, req datasource.S
 				Computed:            true,
 			},
 			"id": schema.StringAttribute{
-				MarkdownDescription: "The id of the cloud 9 environment",
-				Required:            true,
+				MarkdownDescription: "The id of the cloud 9 environment, conflicts with `name` and `filter_tags`",
+				Optional:            true,
+				Computed:            true,
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("name"), path.MatchRoot("filter_tags")),
+					stringvalidator.AtLeastOneOf(path.MatchRoot("id"), path.MatchRoot("name"), path.MatchRoot("filter_tags")),
+				},
 			},
 			"name": schema.StringAttribute{
-				MarkdownDescription: "The name of the environment",
-				Required:            false,
-				Optional:            false,
+				MarkdownDescription: "The name of the environment, can be used instead of `id` to look the environment up",
+				Optional:            true,
 				Computed:            true,
 			},
 			"description": schema.StringAttribute{
@@ -93,6 +112,11 @@ func (ds *SSHEnvironmentDataSource) Schema(ctx context.Context, req datasource.S
 				Optional:            false,
 				Computed:            true,
 			},
+			"filter_tags": schema.MapAttribute{
+				MarkdownDescription: "Only the environments having all these tags match, can be used with `name` instead of `id` to look the environment up",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
 			"tags": schema.MapAttribute{
 				MarkdownDescription: "The tags of the environment.",
 				ElementType:         types.StringType,
@@ -132,22 +156,78 @@ func (ds *SSHEnvironmentDataSource) Read(ctx context.Context, req datasource.Rea
 		return
 	}
 
-	environmentId := data.ID.ValueString()
-	environments, err := ds.client.GetSSHEnvironments(environmentId)
-	if err != nil {
-		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Unable to read environment %s, got error: %s", environmentId, err))
-		return
-	} else if len(environments) == 0 {
-		resp.Diagnostics.AddError("Environment not found", fmt.Sprintf("Unable to read environment %s", environmentId))
+	var environments []aws.Cloud9SSHEnvironment
+	if !data.ID.IsNull() {
+		environmentId := data.ID.ValueString()
+		var err error
+		environments, err = ds.client.GetSSHEnvironments(ctx, environmentId)
+		if err != nil && !aws.IsNotFound(err) {
+			resp.Diagnostics.AddError("Client error", fmt.Sprintf("Unable to read environment %s, got error: %s", environmentId, err))
+			return
+		} else if len(environments) == 0 {
+			resp.Diagnostics.AddError("Environment not found", fmt.Sprintf("Unable to read environment %s", environmentId))
+			return
+		}
+	} else {
+		filter := environmentFilter{
+			Name:            data.Name.ValueString(),
+			EnvironmentType: cloud9.EnvironmentTypeSsh,
+			Tags:            make(map[string]string),
+		}
+		if !data.FilterTags.IsNull() {
+			resp.Diagnostics.Append(data.FilterTags.ElementsAs(ctx, &filter.Tags, false)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+
+		var err error
+		// partial results are an error, they could miss the environment or
+		// one of its duplicates
+		environments, err = findEnvironments(ctx, ds.client, &filter)
+		if err != nil {
+			resp.Diagnostics.AddError("Client error", fmt.Sprintf("Unable to list environments: %s", err))
+			return
+		}
+
+		if len(environments) == 0 {
+			resp.Diagnostics.AddError("Environment not found", fmt.Sprintf("No SSH environment matches %s", filter.String()))
+			return
+		} else if len(environments) > 1 {
+			ids := make([]string, 0, len(environments))
+			for _, environment := range environments {
+				ids = append(ids, environment.EnvironmentId)
+			}
+			resp.Diagnostics.AddError("Multiple environments found", fmt.Sprintf("%d SSH environments match %s: %s, use a more specific filter or the id of the environment", len(environments), filter.String(), strings.Join(ids, ", ")))
+			return
+		}
 	}
 
 	environment := environments[0]
-	diags := convertModelToPlan(&data, &environment)
+	if environment.Type != cloud9.EnvironmentTypeSsh {
+		resp.Diagnostics.AddError("Not an SSH environment", fmt.Sprintf("Environment %s is of type %s", environment.EnvironmentId, environment.Type))
+		return
+	}
+
+	var model SSHEnvironmentModel
+	diags := convertModelToPlan(&model, &environment)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
+	data.Arn = model.Arn
+	data.ID = model.ID
+	data.Name = model.Name
+	data.Description = model.Description
+	data.LoginName = model.LoginName
+	data.Hostname = model.Hostname
+	data.Port = model.Port
+	data.EnvironmentPath = model.EnvironmentPath
+	data.NodePath = model.NodePath
+	data.BastionURL = model.BastionURL
+	data.Tags = model.Tags
+
 	diags = resp.State.Set(ctx, &data)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
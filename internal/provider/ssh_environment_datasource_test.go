@@ -0,0 +1,117 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func TestAccSSHEnvironmentDataSource(t *testing.T) {
+	server := testAccServer(t)
+	envId := server.AddSSHEnvironment("env", fakecloud9.SSHRemote{
+		LoginName:       "ubuntu",
+		Hostname:        "example.com",
+		Port:            2222,
+		EnvironmentPath: "/home/ubuntu",
+		NodePath:        "/usr/local/bin/node",
+		BastionHost:     "jump@bastion.example.com:22",
+	}, map[string]string{"team": "infra"})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + `
+data "awscloud9_ssh_environment" "test" {
+  id = "` + envId + `"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.awscloud9_ssh_environment.test", "arn"),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.test", "name", "env"),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.test", "login_name", "ubuntu"),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.test", "hostname", "example.com"),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.test", "port", "2222"),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.test", "environment_path", "/home/ubuntu"),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.test", "node_path", "/usr/local/bin/node"),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.test", "bastion_url", "jump@bastion.example.com:22"),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.test", "tags.team", "infra"),
+					resource.TestCheckNoResourceAttr("data.awscloud9_ssh_environment.test", "filter_tags.%"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSSHEnvironmentDataSourceLookup(t *testing.T) {
+	server := testAccServer(t)
+	remote := fakecloud9.SSHRemote{LoginName: "ubuntu", Hostname: "example.com", Port: 22}
+	devId := server.AddSSHEnvironment("dev", remote, map[string]string{"team": "infra", "stage": "dev"})
+	prodId := server.AddSSHEnvironment("prod", remote, map[string]string{"team": "infra", "stage": "prod"})
+	server.AddEC2Environment("ec2", map[string]string{"team": "infra", "stage": "dev"})
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + `
+data "awscloud9_ssh_environment" "by_name" {
+  name = "prod"
+}
+
+data "awscloud9_ssh_environment" "by_tags" {
+  filter_tags = {
+    stage = "dev"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.by_name", "id", prodId),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.by_name", "tags.stage", "prod"),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.by_tags", "id", devId),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.by_tags", "name", "dev"),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.by_tags", "filter_tags.%", "1"),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.by_tags", "filter_tags.stage", "dev"),
+					resource.TestCheckResourceAttr("data.awscloud9_ssh_environment.by_tags", "tags.%", "2"),
+				),
+			},
+			// the invalid configurations fail at validation, even on destroy,
+			// so they come before the lookup errors
+			{
+				Config: testAccProviderConfig(server) + `
+data "awscloud9_ssh_environment" "test" {
+  id   = "` + devId + `"
+  name = "dev"
+}
+`,
+				ExpectError: regexp.MustCompile(`(?s)Attribute "name" cannot be specified when "id" is specified`),
+			},
+			{
+				Config: testAccProviderConfig(server) + `
+data "awscloud9_ssh_environment" "test" {}
+`,
+				ExpectError: regexp.MustCompile(`(?s)At least one attribute out of \[id,name,tags\] must be specified`),
+			},
+			{
+				Config: testAccProviderConfig(server) + `
+data "awscloud9_ssh_environment" "test" {
+  name = "missing"
+}
+`,
+				ExpectError: regexp.MustCompile("Environment not found"),
+			},
+			{
+				Config: testAccProviderConfig(server) + `
+data "awscloud9_ssh_environment" "test" {
+  filter_tags = {
+    team = "infra"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile("Multiple environments found"),
+			},
+		},
+	})
+}
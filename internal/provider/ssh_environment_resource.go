@@ -2,11 +2,12 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/aws/aws-sdk-go/service/cloud9"
-	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/objectvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -14,15 +15,18 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/sshcheck"
 )
 
 var (
 	_ resource.Resource                = &SSHEnvironmentResource{}
 	_ resource.ResourceWithConfigure   = &SSHEnvironmentResource{}
 	_ resource.ResourceWithImportState = &SSHEnvironmentResource{}
+	_ resource.ResourceWithModifyPlan  = &SSHEnvironmentResource{}
 )
 
 type SSHEnvironmentResource struct {
@@ -33,8 +37,6 @@ func NewSSHEnvironmentResource() resource.Resource {
 	return &SSHEnvironmentResource{}
 }
 
-type SSHEnvironmentResourceModel = SSHEnvironmentModel
-
 func (rs *SSHEnvironmentResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_ssh_environment"
 }
@@ -75,8 +77,13 @@ func (rs *SSHEnvironmentResource) Schema(ctx context.Context, req resource.Schem
 				MarkdownDescription: "The hostname of the remote machine",
 			},
 			"port": schema.Int64Attribute{
-				Default:  int64default.StaticInt64(22),
-				Computed: true,
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(DEFAULT_SSH_PORT),
+				MarkdownDescription: "The ssh port of the remote machine, defaults to 22",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 65535),
+				},
 			},
 			"environment_path": schema.StringAttribute{
 				Required:            false,
@@ -95,7 +102,12 @@ func (rs *SSHEnvironmentResource) Schema(ctx context.Context, req resource.Schem
 			"bastion_url": schema.StringAttribute{
 				Required:            false,
 				Optional:            true,
-				MarkdownDescription: "The ssh url to a bastion host",
+				Computed:            true,
+				MarkdownDescription: "The ssh url to a bastion host, formatted like `[user@]host[:port]`. Computed from `bastion` when the block is used instead",
+				Validators: []validator.String{
+					validateBastionURL(),
+				},
+				PlanModifiers: []planmodifier.String{bastionURLPlanModifier{}},
 			},
 			"tags": schema.MapAttribute{
 				MarkdownDescription: "A list of tags to attach",
@@ -103,6 +115,41 @@ func (rs *SSHEnvironmentResource) Schema(ctx context.Context, req resource.Schem
 				Required:            false,
 				Optional:            true,
 			},
+			"tags_all": schema.MapAttribute{
+				MarkdownDescription: "The tags of the environment, including the provider `default_tags`",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"validate_connectivity": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Check when planning a new host, port, login name or bastion that the host accepts ssh connections and that the cloud9 public key is in the authorized keys of the login name. The host is reached through the bastion with the keys of the local ssh agent, host keys are not verified",
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"bastion": schema.SingleNestedBlock{
+				MarkdownDescription: "The bastion host to connect through, alternative to `bastion_url`",
+				Attributes: map[string]schema.Attribute{
+					"user": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "The user to log in the bastion with",
+					},
+					"host": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "The hostname of the bastion, required when the block is set",
+					},
+					"port": schema.Int64Attribute{
+						Optional:            true,
+						MarkdownDescription: "The ssh port of the bastion, defaults to 22",
+						Validators: []validator.Int64{
+							int64validator.Between(1, 65535),
+						},
+					},
+				},
+				Validators: []validator.Object{
+					objectvalidator.AlsoRequires(path.MatchRelative().AtName("host")),
+					objectvalidator.ConflictsWith(path.MatchRoot("bastion_url")),
+				},
+			},
 		},
 	}
 }
@@ -138,47 +185,51 @@ func (rs *SSHEnvironmentResource) Create(ctx context.Context, req resource.Creat
 	request.Name = plan.Name.ValueString()
 	request.LoginName = plan.LoginName.ValueString()
 	request.Hostname = plan.Hostname.ValueString()
-	request.Port = int16(plan.Port.ValueInt64())
+	request.Port = int(plan.Port.ValueInt64())
 
-	calculatedTags := make([]aws.Tag, 0)
-	for key, val := range plan.Tags.Elements() {
-		tfVal, err := val.ToTerraformValue(ctx)
-		if err != nil {
-			resp.Diagnostics.AddError("Convert Error", "Error converting value from tag")
-		}
-		var strVal string
-		if err = tfVal.As(&strVal); err != nil {
-			resp.Diagnostics.AddError("Convert Error", "Error converting value from tag")
-		}
-		calculatedTags = append(calculatedTags, aws.Tag{
-			Key:   key,
-			Value: strVal,
-		})
+	request.Tags, diags = tagsFromMap(ctx, plan.TagsAll)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.Description.IsNull() {
+		request.Description = plan.Description.ValueString()
 	}
-	request.Tags = calculatedTags
 
 	if !plan.BastionURL.IsNull() {
 		request.BastionHost = plan.BastionURL.ValueString()
 	}
 
-	if !plan.NodePath.IsNull() {
+	// node_path and environment_path are unknown when not configured, the
+	// service then picks a default value
+	if !plan.NodePath.IsNull() && !plan.NodePath.IsUnknown() {
 		request.NodePath = plan.NodePath.ValueString()
 	}
 
-	if !plan.EnvironmentPath.IsNull() {
-		request.EnvironmentPath = plan.ID.ValueString()
-	} else {
-		plan.EnvironmentPath = types.StringValue("")
+	if !plan.EnvironmentPath.IsNull() && !plan.EnvironmentPath.IsUnknown() {
+		request.EnvironmentPath = plan.EnvironmentPath.ValueString()
 	}
 
-	environment, err := rs.client.CreateEnvironmentSSH(&request)
-	if err != nil {
+	environment, err := rs.client.CreateEnvironmentSSH(ctx, &request)
+	if aws.IsConflict(err) {
+		resp.Diagnostics.AddError("Environment already exists", fmt.Sprintf("Unable to create environment %s, an environment with the same name already exists: %s", plan.Name.ValueString(), err.Error()))
+		return
+	} else if err != nil {
 		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Unable to create environment %s, got error: %s", request.Name, err))
 		return
 	}
 
 	plan.ID = types.StringValue(environment.EnvironmentId)
-	readResults, err := rs.client.GetSSHEnvironments(environment.EnvironmentId)
+	err = rs.client.WaitEnvironmentReady(ctx, environment.EnvironmentId)
+	if err != nil {
+		// keep the environment in the state so that it gets tainted
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), environment.EnvironmentId)...)
+		resp.Diagnostics.AddError("Environment creation failed", fmt.Sprintf("Environment %s did not become ready: %s", environment.EnvironmentId, err.Error()))
+		return
+	}
+
+	readResults, err := rs.client.GetSSHEnvironments(ctx, environment.EnvironmentId)
 	if err != nil {
 		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Could not read environment %s: %s", environment.EnvironmentId, err.Error()))
 		return
@@ -189,6 +240,7 @@ func (rs *SSHEnvironmentResource) Create(ctx context.Context, req resource.Creat
 	readResult := readResults[0]
 	plan.NodePath = types.StringValue(readResult.NodePath)
 	plan.EnvironmentPath = types.StringValue(readResult.EnvironmentPath)
+	plan.Port = types.Int64Value(int64(readResult.Port))
 	plan.Arn = types.StringValue(readResult.Arn)
 
 	diags = resp.State.Set(ctx, plan)
@@ -198,7 +250,86 @@ func (rs *SSHEnvironmentResource) Create(ctx context.Context, req resource.Creat
 	}
 }
 
-func convertModelToPlan(state *SSHEnvironmentResourceModel, environment *aws.Cloud9SSHEnvironment) diag.Diagnostics {
+func (rs *SSHEnvironmentResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	modifyPlanTagsAll(ctx, rs.client, req, resp)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	rs.validateConnectivity(ctx, req, resp)
+}
+
+// validateConnectivity runs the ssh checks when validate_connectivity is set
+// and the connection settings are created or changed.
+func (rs *SSHEnvironmentResource) validateConnectivity(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || rs.client == nil {
+		return
+	}
+
+	var plan SSHEnvironmentResourceModel
+	resp.Diagnostics.Append(resp.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || !plan.ValidateConnectivity.ValueBool() {
+		return
+	}
+
+	// unknown settings are checked during the apply
+	if plan.LoginName.IsUnknown() || plan.Hostname.IsUnknown() || plan.Port.IsUnknown() || plan.BastionURL.IsUnknown() {
+		return
+	}
+
+	if !req.State.Raw.IsNull() {
+		var state SSHEnvironmentResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if state.ValidateConnectivity.ValueBool() && state.LoginName.Equal(plan.LoginName) && state.Hostname.Equal(plan.Hostname) &&
+			state.Port.Equal(plan.Port) && state.BastionURL.Equal(plan.BastionURL) {
+			return
+		}
+	}
+
+	config := sshcheck.Config{
+		Target: sshcheck.Endpoint{
+			User: plan.LoginName.ValueString(),
+			Host: plan.Hostname.ValueString(),
+			Port: int(plan.Port.ValueInt64()),
+		},
+	}
+	if !plan.BastionURL.IsNull() {
+		bastion, err := parseBastionURL(plan.BastionURL.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("bastion_url"), "Invalid bastion URL", err.Error())
+			return
+		}
+		config.Bastion = &sshcheck.Endpoint{
+			User: bastion.User,
+			Host: bastion.Host,
+			Port: bastion.Port,
+		}
+		// cloud9 logs in the bastion with the login name when none is set
+		if len(bastion.User) == 0 {
+			config.Bastion.User = config.Target.User
+		}
+	}
+
+	publicKey, err := rs.client.GetUserPublicKey(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Could not get the public key of the user: %s", err.Error()))
+		return
+	}
+	config.PublicKey = publicKey.PublicKey
+
+	err = sshcheck.Check(ctx, &config)
+	var checkErr *sshcheck.Error
+	if errors.As(err, &checkErr) {
+		resp.Diagnostics.AddError(checkErr.Summary(), fmt.Sprintf("The environment would not be usable, %s. Set validate_connectivity to false to skip this check.", checkErr.Error()))
+	} else if err != nil {
+		resp.Diagnostics.AddError("Connectivity check failed", fmt.Sprintf("Could not check the connectivity to %s: %s", config.Target.Address(), err.Error()))
+	}
+}
+
+func convertModelToPlan(state *SSHEnvironmentModel, environment *aws.Cloud9SSHEnvironment) diag.Diagnostics {
 	state.Arn = types.StringValue(environment.Arn)
 	state.ID = basetypes.NewStringValue(environment.EnvironmentId)
 	if len(environment.BastionHost) > 0 {
@@ -218,14 +349,49 @@ func convertModelToPlan(state *SSHEnvironmentResourceModel, environment *aws.Clo
 	state.Hostname = basetypes.NewStringValue(environment.Hostname)
 	state.EnvironmentPath = basetypes.NewStringValue(environment.EnvironmentPath)
 	state.NodePath = basetypes.NewStringValue(environment.NodePath)
-	typedTags := make(map[string]attr.Value)
-	for _, tag := range environment.Tags {
-		typedTags[tag.Key] = basetypes.NewStringValue(tag.Value)
-	}
 
 	var diags diag.Diagnostics
 
-	state.Tags, diags = basetypes.NewMapValue(types.StringType, typedTags)
+	state.Tags, diags = tagsToMap(environment.Tags)
+	return diags
+}
+
+// convertSSHEnvironmentToResourceModel updates the state from the
+// environment, the default tags are only reported in tags_all.
+func convertSSHEnvironmentToResourceModel(ctx context.Context, client *aws.AWSCloud9Client, state *SSHEnvironmentResourceModel, environment *aws.Cloud9SSHEnvironment) diag.Diagnostics {
+	var model SSHEnvironmentModel
+	diags := convertModelToPlan(&model, environment)
+	if diags.HasError() {
+		return diags
+	}
+
+	state.Arn = model.Arn
+	state.ID = model.ID
+	state.Name = model.Name
+	state.Description = model.Description
+	state.LoginName = model.LoginName
+	state.Hostname = model.Hostname
+	state.Port = model.Port
+	state.EnvironmentPath = model.EnvironmentPath
+	state.NodePath = model.NodePath
+	if state.BastionURL.IsNull() || !bastionURLsEqual(state.BastionURL.ValueString(), environment.BastionHost) {
+		state.BastionURL = model.BastionURL
+	}
+	// the block is only reported when it is used instead of bastion_url
+	if state.Bastion != nil {
+		current := state.Bastion
+		state.Bastion = nil
+		if len(environment.BastionHost) > 0 {
+			bastion, err := bastionToModel(environment.BastionHost, current)
+			if err == nil {
+				state.Bastion = bastion
+			}
+		}
+	}
+
+	var d diag.Diagnostics
+	state.Tags, state.TagsAll, d = readTags(ctx, client, environment.Tags, state.Tags)
+	diags.Append(d...)
 	return diags
 }
 
@@ -239,19 +405,25 @@ func (rs *SSHEnvironmentResource) Read(ctx context.Context, req resource.ReadReq
 	}
 
 	envId := state.ID.ValueString()
-	environments, err := rs.client.GetSSHEnvironments(envId)
-	if err != nil {
+	environments, err := rs.client.GetSSHEnvironments(ctx, envId)
+	if err != nil && !aws.IsNotFound(err) {
 		resp.Diagnostics.AddError("Error fetching env", fmt.Sprintf("Could not fetch env %s: %s", envId, err.Error()))
 		return
 	}
 
 	if len(environments) == 0 {
-		resp.Diagnostics.AddError("Environment not found", fmt.Sprintf("Environment not found: %s", envId))
+		// the environment was deleted outside of terraform
+		resp.State.RemoveResource(ctx)
 		return
 	}
 
 	environment := environments[0]
-	diags = convertModelToPlan(&state, &environment)
+	if environment.Type != cloud9.EnvironmentTypeSsh {
+		resp.Diagnostics.AddError("Not an SSH environment", fmt.Sprintf("Environment %s is of type %s", envId, environment.Type))
+		return
+	}
+
+	diags = convertSSHEnvironmentToResourceModel(ctx, rs.client, &state, &environment)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -273,13 +445,19 @@ func (rs *SSHEnvironmentResource) Delete(ctx context.Context, req resource.Delet
 	}
 
 	envId := state.ID.ValueString()
-	_, err := rs.client.Cloud9.DeleteEnvironment(&cloud9.DeleteEnvironmentInput{
-		EnvironmentId: &envId,
-	})
-	if err != nil {
+	err := rs.client.DeleteEnvironment(ctx, envId)
+	if aws.IsNotFound(err) {
+		return
+	} else if err != nil {
 		resp.Diagnostics.AddError("Error deleting env", fmt.Sprintf("Could not delete environment %s: %s", envId, err.Error()))
 		return
 	}
+
+	err = rs.client.WaitEnvironmentDeleted(ctx, envId)
+	if err != nil {
+		resp.Diagnostics.AddError("Error deleting env", fmt.Sprintf("Environment %s was not deleted: %s", envId, err.Error()))
+		return
+	}
 }
 
 func (rs *SSHEnvironmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -290,13 +468,6 @@ func (rs *SSHEnvironmentResource) Update(ctx context.Context, req resource.Updat
 		return
 	}
 
-	planTags := make(map[string]string)
-	diags = plan.Tags.ElementsAs(ctx, &planTags, false)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
 	var state SSHEnvironmentResourceModel
 	diags = req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -304,49 +475,6 @@ func (rs *SSHEnvironmentResource) Update(ctx context.Context, req resource.Updat
 		return
 	}
 
-	stateTags := make(map[string]string)
-	diags = state.Tags.ElementsAs(ctx, &stateTags, false)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	removedTags := make([]*string, 0)
-	addedTags := make([]*cloud9.Tag, 0)
-
-	for stateKey, stateValue := range stateTags {
-		found := false
-		for planKey, planValue := range planTags {
-			if planKey == stateKey {
-				if stateValue != planValue {
-					key := strings.Clone(planKey)
-					val := strings.Clone(planValue)
-					addedTags = append(addedTags, &cloud9.Tag{
-						Key:   &key,
-						Value: &val,
-					})
-				}
-				found = true
-				break
-			}
-		}
-		if !found {
-			key := strings.Clone(stateKey)
-			removedTags = append(removedTags, &key)
-		}
-	}
-
-	for planKey, planValue := range planTags {
-		if _, ok := stateTags[planKey]; !ok {
-			key := strings.Clone(planKey)
-			val := strings.Clone(planValue)
-			addedTags = append(addedTags, &cloud9.Tag{
-				Key:   &key,
-				Value: &val,
-			})
-		}
-	}
-
 	envId := plan.ID.ValueString()
 	arn := plan.Arn.ValueString()
 	updatedEnv := aws.Cloud9SSHEnvironment{
@@ -357,7 +485,7 @@ func (rs *SSHEnvironmentResource) Update(ctx context.Context, req resource.Updat
 		Hostname:      plan.Hostname.ValueString(),
 	}
 	if !plan.Port.IsNull() {
-		updatedEnv.Port = int16(plan.Port.ValueInt64())
+		updatedEnv.Port = int(plan.Port.ValueInt64())
 	}
 	if !plan.EnvironmentPath.IsNull() {
 		updatedEnv.EnvironmentPath = plan.EnvironmentPath.ValueString()
@@ -369,28 +497,30 @@ func (rs *SSHEnvironmentResource) Update(ctx context.Context, req resource.Updat
 		updatedEnv.BastionHost = plan.BastionURL.ValueString()
 	}
 
-	err := rs.client.UpdateEnvironment(updatedEnv)
+	err := rs.client.UpdateEnvironment(ctx, updatedEnv)
 	if err != nil {
 		resp.Diagnostics.AddError("Error updating environment", fmt.Sprintf("Error updating environment %s: %s", envId, err.Error()))
 		return
 	}
 
-	_, err = rs.client.Cloud9.UntagResource(&cloud9.UntagResourceInput{
-		ResourceARN: &arn,
-		TagKeys:     removedTags,
-	})
+	resp.Diagnostics.Append(updateTags(ctx, rs.client, envId, arn, state.TagsAll, plan.TagsAll)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
+	// the remote settings are read back, as normalized by the service
+	readResults, err := rs.client.GetSSHEnvironments(ctx, envId)
 	if err != nil {
-		resp.Diagnostics.AddError("Error untagging environment", fmt.Sprintf("Error untagging environment %s: %s", envId, err.Error()))
+		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Could not read environment %s: %s", envId, err.Error()))
+		return
+	} else if len(readResults) == 0 {
+		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Could not read environment %s", envId))
 		return
 	}
 
-	_, err = rs.client.Cloud9.TagResource(&cloud9.TagResourceInput{
-		ResourceARN: &arn,
-		Tags:        addedTags,
-	})
-	if err != nil {
-		resp.Diagnostics.AddError("Error tagging environment", fmt.Sprintf("Error tagging environment %s: %s", envId, err.Error()))
+	diags = convertSSHEnvironmentToResourceModel(ctx, rs.client, &plan, &readResults[0])
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
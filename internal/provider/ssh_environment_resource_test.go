@@ -0,0 +1,589 @@
+package provider
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakessh"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func TestAccSSHEnvironmentResource(t *testing.T) {
+	server := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + `
+resource "awscloud9_ssh_environment" "test" {
+  name        = "env"
+  description = "an environment"
+  login_name  = "ubuntu"
+  hostname    = "example.com"
+
+  tags = {
+    team = "infra"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("awscloud9_ssh_environment.test", "id"),
+					resource.TestCheckResourceAttrSet("awscloud9_ssh_environment.test", "arn"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "name", "env"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "description", "an environment"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "port", "22"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "environment_path", fakecloud9.DEFAULT_ENVIRONMENT_PATH),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "node_path", fakecloud9.DEFAULT_NODE_PATH),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags.%", "1"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags.team", "infra"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(server) + `
+resource "awscloud9_ssh_environment" "test" {
+  name        = "renamed"
+  description = "an environment"
+  login_name  = "ec2-user"
+  hostname    = "other.example.com"
+
+  tags = {
+    owner = "me"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "name", "renamed"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags.%", "1"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags.owner", "me"),
+					testAccCheckSSHEnvironment(server, "awscloud9_ssh_environment.test", "renamed", "ec2-user", "other.example.com"),
+				),
+			},
+			{
+				ResourceName:      "awscloud9_ssh_environment.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccSSHEnvironmentFullConfig(description string, remote fakecloud9.SSHRemote) string {
+	return fmt.Sprintf(`
+resource "awscloud9_ssh_environment" "test" {
+  name        = "full"
+  description = %q
+  login_name  = %q
+  hostname    = %q
+
+  port             = %d
+  environment_path = %q
+  node_path        = %q
+  bastion_url      = %q
+}
+`, description, remote.LoginName, remote.Hostname, remote.Port, remote.EnvironmentPath, remote.NodePath, remote.BastionHost)
+}
+
+func TestAccSSHEnvironmentResourceAttributes(t *testing.T) {
+	server := testAccServer(t)
+	created := fakecloud9.SSHRemote{
+		LoginName:       "ubuntu",
+		Hostname:        "example.com",
+		Port:            2222,
+		EnvironmentPath: "/home/ubuntu/work",
+		NodePath:        "/opt/node/bin/node",
+		BastionHost:     "jump@bastion.example.com:22",
+	}
+	updated := fakecloud9.SSHRemote{
+		LoginName:       "ec2-user",
+		Hostname:        "other.example.com",
+		Port:            42,
+		EnvironmentPath: "/srv/work",
+		NodePath:        "/usr/local/bin/node",
+		BastionHost:     "admin@bastion.example.com:2022",
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + testAccSSHEnvironmentFullConfig("created", created),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "description", "created"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "port", "2222"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "environment_path", created.EnvironmentPath),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "node_path", created.NodePath),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "bastion_url", created.BastionHost),
+					testAccCheckSSHEnvironmentRemote(server, "awscloud9_ssh_environment.test", "created", created),
+				),
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccSSHEnvironmentFullConfig("updated", updated),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "description", "updated"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "port", "42"),
+					testAccCheckSSHEnvironmentRemote(server, "awscloud9_ssh_environment.test", "updated", updated),
+				),
+			},
+			{
+				ResourceName:      "awscloud9_ssh_environment.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccSSHEnvironmentResourceInvalidPort(t *testing.T) {
+	server := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + `
+resource "awscloud9_ssh_environment" "test" {
+  name       = "env"
+  login_name = "ubuntu"
+  hostname   = "example.com"
+  port       = 65536
+}
+`,
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Invalid Attribute Value"),
+			},
+		},
+	})
+}
+
+func testAccSSHEnvironmentBastionConfig(bastion string) string {
+	return `
+resource "awscloud9_ssh_environment" "test" {
+  name       = "env"
+  login_name = "ubuntu"
+  hostname   = "example.com"
+` + bastion + `
+}
+`
+}
+
+func TestAccSSHEnvironmentResourceBastion(t *testing.T) {
+	server := testAccServer(t)
+	remote := fakecloud9.SSHRemote{
+		LoginName:       "ubuntu",
+		Hostname:        "example.com",
+		Port:            22,
+		EnvironmentPath: fakecloud9.DEFAULT_ENVIRONMENT_PATH,
+		NodePath:        fakecloud9.DEFAULT_NODE_PATH,
+		BastionHost:     "jump@bastion.example.com:22",
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + testAccSSHEnvironmentBastionConfig(`
+  bastion {
+    user = "jump"
+    host = "bastion.example.com"
+  }
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "bastion_url", remote.BastionHost),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "bastion.user", "jump"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "bastion.host", "bastion.example.com"),
+					resource.TestCheckNoResourceAttr("awscloud9_ssh_environment.test", "bastion.port"),
+					testAccCheckSSHEnvironmentRemote(server, "awscloud9_ssh_environment.test", "", remote),
+				),
+			},
+			{
+				// equivalent url, the stored url is kept and no changes are
+				// planned after the block was removed
+				Config: testAccProviderConfig(server) + testAccSSHEnvironmentBastionConfig(`
+  bastion_url = "jump@bastion.example.com"
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "bastion_url", remote.BastionHost),
+					resource.TestCheckNoResourceAttr("awscloud9_ssh_environment.test", "bastion.host"),
+					testAccCheckSSHEnvironmentRemote(server, "awscloud9_ssh_environment.test", "", remote),
+				),
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccSSHEnvironmentBastionConfig(`
+  bastion {
+    user = "jump"
+    host = "bastion.example.com"
+    port = 2222
+  }
+`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "bastion_url", "jump@bastion.example.com:2222"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "bastion.port", "2222"),
+				),
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccSSHEnvironmentBastionConfig(""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("awscloud9_ssh_environment.test", "bastion_url"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSSHEnvironmentResourceInvalidBastion(t *testing.T) {
+	server := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + testAccSSHEnvironmentBastionConfig(`
+  bastion_url = "ssh://jump@bastion.example.com"
+`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Invalid bastion URL"),
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccSSHEnvironmentBastionConfig(`
+  bastion_url = "jump@bastion.example.com"
+
+  bastion {
+    host = "bastion.example.com"
+  }
+`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Invalid Attribute Combination"),
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccSSHEnvironmentBastionConfig(`
+  bastion {
+    user = "jump"
+  }
+`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Invalid Attribute Combination"),
+			},
+		},
+	})
+}
+
+// testAccCheckSSHEnvironmentRemote checks the description and every remote
+// setting stored by the fake server.
+func testAccCheckSSHEnvironmentRemote(server *fakecloud9.Server, name, description string, remote fakecloud9.SSHRemote) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource %s not found", name)
+		}
+
+		env := server.Environment(rs.Primary.Attributes["id"])
+		if env == nil {
+			return fmt.Errorf("environment %s does not exist", rs.Primary.Attributes["id"])
+		}
+		if env.Description != description {
+			return fmt.Errorf("expected description %q, got %q", description, env.Description)
+		}
+		if *env.Remote != remote {
+			return fmt.Errorf("expected remote %+v, got %+v", remote, *env.Remote)
+		}
+		return nil
+	}
+}
+
+func TestAccSSHEnvironmentResourceDisappears(t *testing.T) {
+	server := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + `
+resource "awscloud9_ssh_environment" "test" {
+  name       = "env"
+  login_name = "ubuntu"
+  hostname   = "example.com"
+}
+`,
+				Check:              testAccCheckSSHEnvironmentDisappears(server, "awscloud9_ssh_environment.test"),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+// testAccCheckSSHEnvironmentDisappears deletes the environment behind
+// terraform's back.
+func testAccCheckSSHEnvironmentDisappears(server *fakecloud9.Server, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource %s not found", name)
+		}
+
+		server.RemoveEnvironment(rs.Primary.Attributes["id"])
+		return nil
+	}
+}
+
+// testAccCheckSSHEnvironment checks the environment stored by the fake server.
+func testAccCheckSSHEnvironment(server *fakecloud9.Server, name, envName, loginName, hostname string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource %s not found", name)
+		}
+
+		env := server.Environment(rs.Primary.Attributes["id"])
+		if env == nil {
+			return fmt.Errorf("environment %s does not exist", rs.Primary.Attributes["id"])
+		}
+		if env.Name != envName || env.Remote.LoginName != loginName || env.Remote.Hostname != hostname {
+			return fmt.Errorf("unexpected environment %s: %s@%s", env.Name, env.Remote.LoginName, env.Remote.Hostname)
+		}
+		return nil
+	}
+}
+
+func testAccSSHEnvironmentTagsConfig(server *fakecloud9.Server, stage string) string {
+	return testAccProviderConfigWithBlocks(server, fmt.Sprintf(`
+  default_tags {
+    tags = {
+      stage = %q
+      team  = "infra"
+    }
+  }
+
+  ignore_tags {
+    keys = ["owner"]
+  }
+`, stage)) + `
+resource "awscloud9_ssh_environment" "test" {
+  name       = "env"
+  login_name = "ubuntu"
+  hostname   = "example.com"
+
+  tags = {
+    team = "ops"
+  }
+}
+`
+}
+
+func TestAccSSHEnvironmentResourceTags(t *testing.T) {
+	server := testAccServer(t)
+	var envId string
+	var tagCalls int
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSSHEnvironmentTagsConfig(server, "dev"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckResourceID("awscloud9_ssh_environment.test", &envId),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags.%", "1"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags.team", "ops"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags_all.%", "2"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags_all.stage", "dev"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags_all.team", "ops"),
+					testAccCheckTags(server, &envId, map[string]string{"stage": "dev", "team": "ops"}),
+				),
+			},
+			{
+				// tags changed outside of terraform are reverted, the
+				// ignored ones are left untouched
+				PreConfig: func() {
+					server.SetTag(envId, "extra", "value")
+					server.SetTag(envId, "stage", "prod")
+					server.SetTag(envId, "owner", "someone")
+				},
+				Config: testAccSSHEnvironmentTagsConfig(server, "dev"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags.%", "1"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags_all.%", "2"),
+					testAccCheckTags(server, &envId, map[string]string{"stage": "dev", "team": "ops", "owner": "someone"}),
+				),
+			},
+			{
+				// nothing changed, the tags are not touched
+				PreConfig: func() {
+					tagCalls = server.Calls("TagResource") + server.Calls("UntagResource")
+				},
+				Config: testAccSSHEnvironmentTagsConfig(server, "dev"),
+				Check: func(s *terraform.State) error {
+					if calls := server.Calls("TagResource") + server.Calls("UntagResource"); calls != tagCalls {
+						return fmt.Errorf("expected no tag calls, got %d", calls-tagCalls)
+					}
+					return nil
+				},
+			},
+			{
+				Config: testAccSSHEnvironmentTagsConfig(server, "prod"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags.%", "1"),
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "tags_all.stage", "prod"),
+					testAccCheckTags(server, &envId, map[string]string{"stage": "prod", "team": "ops", "owner": "someone"}),
+				),
+			},
+			{
+				// ignored tags would never be read back
+				Config: strings.Replace(testAccSSHEnvironmentTagsConfig(server, "prod"), `team = "ops"`, `team = "ops"
+    owner = "me"`, 1),
+				ExpectError: regexp.MustCompile("Ignored tag configured"),
+			},
+			{
+				Config:   testAccSSHEnvironmentTagsConfig(server, "prod"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+// testAccCheckResourceID stores the id of a resource for the next steps.
+func testAccCheckResourceID(name string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("resource %s not found", name)
+		}
+
+		*id = rs.Primary.Attributes["id"]
+		return nil
+	}
+}
+
+// testAccCheckTags checks the tags stored by the fake server.
+func testAccCheckTags(server *fakecloud9.Server, envId *string, expected map[string]string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		tags := server.Tags(*envId)
+		if !reflect.DeepEqual(tags, expected) {
+			return fmt.Errorf("expected tags %v, got %v", expected, tags)
+		}
+		return nil
+	}
+}
+
+func testAccSSHEnvironmentConnectivityConfig(server *fakecloud9.Server, loginName string, target *fakessh.Server, bastion string) string {
+	return testAccProviderConfig(server) + fmt.Sprintf(`
+resource "awscloud9_ssh_environment" "test" {
+  name       = "env"
+  login_name = %q
+  hostname   = %q
+  port       = %d
+  %s
+
+  validate_connectivity = true
+}
+`, loginName, target.Host, target.Port, bastion)
+}
+
+// testAccSSHAgent serves an ssh agent holding a new key for the duration of
+// the test and returns the public key.
+func testAccSSHAgent(t *testing.T) ssh.PublicKey {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: privateKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", socket)
+
+	signers, err := keyring.Signers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signers[0].PublicKey()
+}
+
+func TestAccSSHEnvironmentResourceConnectivity(t *testing.T) {
+	server := testAccServer(t)
+	target := fakessh.NewServer()
+	t.Cleanup(target.Close)
+	bastion := fakessh.NewServer()
+	t.Cleanup(bastion.Close)
+
+	target.Authorize("ubuntu", server.PublicKey)
+	bastion.Authorize("jump", server.PublicKey)
+	bastion.AuthorizeKey("jump", testAccSSHAgent(t))
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccSSHEnvironmentConnectivityConfig(server, "ubuntu", target, ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_ssh_environment.test", "validate_connectivity", "true"),
+				),
+			},
+			{
+				Config:      testAccSSHEnvironmentConnectivityConfig(server, "root", target, ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Public key not authorized"),
+			},
+			{
+				Config: testAccSSHEnvironmentConnectivityConfig(server, "ubuntu", target, `bastion_url = "jump@`+bastion.Addr()+`"`),
+				Check: func(s *terraform.State) error {
+					if bastion.Forwards() == 0 {
+						return fmt.Errorf("expected the host to be reached through the bastion")
+					}
+					return nil
+				},
+			},
+			{
+				Config:      testAccSSHEnvironmentConnectivityConfig(server, "ubuntu", target, `bastion_url = "admin@`+bastion.Addr()+`"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Public key not authorized"),
+			},
+		},
+	})
+}
+
+func TestAccSSHEnvironmentResourceUnreachable(t *testing.T) {
+	server := testAccServer(t)
+	target := fakessh.NewServer()
+	target.Close()
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccSSHEnvironmentConnectivityConfig(server, "ubuntu", target, ""),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("Host unreachable"),
+			},
+		},
+	})
+}
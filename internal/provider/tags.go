@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
+)
+
+// Taggable resources expose the configured `tags` and the computed
+// `tags_all`, which also holds the provider default tags. tags_all is what
+// is sent to the service, tags is read back from it without the default
+// tags so that out-of-band changes show up as drift on either attribute.
+
+func tagsFromMap(ctx context.Context, tags types.Map) ([]aws.Tag, diag.Diagnostics) {
+	tagMap := make(map[string]string)
+	diags := tags.ElementsAs(ctx, &tagMap, false)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	return mapToTags(tagMap), diags
+}
+
+func tagsToMap(tags []aws.Tag) (types.Map, diag.Diagnostics) {
+	typedTags := make(map[string]attr.Value)
+	for _, tag := range tags {
+		typedTags[tag.Key] = types.StringValue(tag.Value)
+	}
+
+	return types.MapValue(types.StringType, typedTags)
+}
+
+func mapToTags(tags map[string]string) []aws.Tag {
+	res := make([]aws.Tag, 0, len(tags))
+	for key, value := range tags {
+		res = append(res, aws.Tag{
+			Key:   key,
+			Value: value,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Key < res[j].Key
+	})
+	return res
+}
+
+func stringMap(ctx context.Context, tags types.Map) (map[string]string, diag.Diagnostics) {
+	res := make(map[string]string)
+	if tags.IsNull() {
+		return res, nil
+	}
+	diags := tags.ElementsAs(ctx, &res, false)
+	return res, diags
+}
+
+// diffTags returns the keys to remove and the tags to add or overwrite in
+// order to go from stateTags to planTags.
+func diffTags(stateTags, planTags map[string]string) ([]string, []aws.Tag) {
+	removedTags := make([]string, 0)
+	addedTags := make(map[string]string)
+
+	for key := range stateTags {
+		if _, ok := planTags[key]; !ok {
+			removedTags = append(removedTags, key)
+		}
+	}
+	sort.Strings(removedTags)
+
+	for key, value := range planTags {
+		if stateValue, ok := stateTags[key]; !ok || stateValue != value {
+			addedTags[key] = value
+		}
+	}
+
+	return removedTags, mapToTags(addedTags)
+}
+
+func tagsConfig(client *aws.AWSCloud9Client) *aws.TagsConfig {
+	if client == nil {
+		return &aws.TagsConfig{}
+	}
+	return client.TagsConfig()
+}
+
+// planTagsAll merges the configured tags with the default tags, tags_all is
+// unknown as long as one of the configured tags is. Configuring an ignored
+// tag is an error.
+func planTagsAll(ctx context.Context, config *aws.TagsConfig, tags types.Map) (types.Map, diag.Diagnostics) {
+	if tags.IsUnknown() {
+		return types.MapUnknown(types.StringType), nil
+	}
+	for _, value := range tags.Elements() {
+		if value.IsUnknown() {
+			return types.MapUnknown(types.StringType), nil
+		}
+	}
+
+	configured, diags := stringMap(ctx, tags)
+	if diags.HasError() {
+		return types.MapNull(types.StringType), diags
+	}
+
+	// ignored tags are never read back, they would show up as drift
+	for _, tag := range mapToTags(configured) {
+		if config.IsIgnored(tag.Key) {
+			diags.AddAttributeError(path.Root("tags").AtMapKey(tag.Key), "Ignored tag configured",
+				fmt.Sprintf("Tag %s matches the provider ignore_tags or the reserved aws: prefix, it cannot be set on the resource.", tag.Key))
+		}
+	}
+	if diags.HasError() {
+		return types.MapNull(types.StringType), diags
+	}
+
+	return tagsToMap(mapToTags(config.MergeDefaults(configured)))
+}
+
+// modifyPlanTagsAll plans tags_all for a taggable resource, so that changes
+// of the default tags trigger an update.
+func modifyPlanTagsAll(ctx context.Context, client *aws.AWSCloud9Client, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// the resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var tags types.Map
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("tags"), &tags)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tagsAll, diags := planTagsAll(ctx, tagsConfig(client), tags)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("tags_all"), tagsAll)...)
+}
+
+// readTags returns tags and tags_all from the tags of the environment,
+// configured is the tags attribute of the current state.
+func readTags(ctx context.Context, client *aws.AWSCloud9Client, remote []aws.Tag, configured types.Map) (types.Map, types.Map, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	tagsAll := make(map[string]string, len(remote))
+	for _, tag := range remote {
+		tagsAll[tag.Key] = tag.Value
+	}
+
+	configuredTags, d := stringMap(ctx, configured)
+	diags.Append(d...)
+	if diags.HasError() {
+		return types.MapNull(types.StringType), types.MapNull(types.StringType), diags
+	}
+
+	resourceTags := tagsConfig(client).ResourceTags(tagsAll, configuredTags)
+
+	tags := types.MapNull(types.StringType)
+	if len(resourceTags) > 0 || !configured.IsNull() {
+		tags, d = tagsToMap(mapToTags(resourceTags))
+		diags.Append(d...)
+	}
+
+	typedTagsAll, d := tagsToMap(remote)
+	diags.Append(d...)
+
+	return tags, typedTagsAll, diags
+}
+
+// updateTags tags and untags an environment to go from the tags_all of the
+// state to the planned one, no call is made when they are equal.
+func updateTags(ctx context.Context, client *aws.AWSCloud9Client, envId, arn string, stateTagsAll, planTagsAll types.Map) diag.Diagnostics {
+	stateTags, diags := stringMap(ctx, stateTagsAll)
+	if diags.HasError() {
+		return diags
+	}
+	planTags, diags := stringMap(ctx, planTagsAll)
+	if diags.HasError() {
+		return diags
+	}
+
+	removedTags, addedTags := diffTags(stateTags, planTags)
+	err := client.UpdateTags(ctx, arn, removedTags, addedTags)
+	if err != nil {
+		diags.AddError("Error tagging environment", fmt.Sprintf("Error updating tags of environment %s: %s", envId, err.Error()))
+	}
+	return diags
+}
@@ -15,3 +15,36 @@ type SSHEnvironmentModel struct {
 	BastionURL      types.String `tfsdk:"bastion_url"`
 	Tags            types.Map    `tfsdk:"tags"`
 }
+
+type SSHEnvironmentResourceModel struct {
+	Arn             types.String  `tfsdk:"arn"`
+	ID              types.String  `tfsdk:"id"`
+	Name            types.String  `tfsdk:"name"`
+	Description     types.String  `tfsdk:"description"`
+	LoginName       types.String  `tfsdk:"login_name"`
+	Hostname        types.String  `tfsdk:"hostname"`
+	Port            types.Int64   `tfsdk:"port"`
+	EnvironmentPath types.String  `tfsdk:"environment_path"`
+	NodePath        types.String  `tfsdk:"node_path"`
+	BastionURL      types.String  `tfsdk:"bastion_url"`
+	Bastion         *bastionModel `tfsdk:"bastion"`
+	Tags            types.Map     `tfsdk:"tags"`
+	TagsAll         types.Map     `tfsdk:"tags_all"`
+
+	ValidateConnectivity types.Bool `tfsdk:"validate_connectivity"`
+}
+
+type EC2EnvironmentModel struct {
+	Arn                      types.String `tfsdk:"arn"`
+	ID                       types.String `tfsdk:"id"`
+	Name                     types.String `tfsdk:"name"`
+	Description              types.String `tfsdk:"description"`
+	InstanceType             types.String `tfsdk:"instance_type"`
+	ImageID                  types.String `tfsdk:"image_id"`
+	SubnetID                 types.String `tfsdk:"subnet_id"`
+	ConnectionType           types.String `tfsdk:"connection_type"`
+	AutomaticStopTimeMinutes types.Int64  `tfsdk:"automatic_stop_time_minutes"`
+	OwnerArn                 types.String `tfsdk:"owner_arn"`
+	Tags                     types.Map    `tfsdk:"tags"`
+	TagsAll                  types.Map    `tfsdk:"tags_all"`
+}
@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
+	"golang.org/x/crypto/ssh"
+)
+
+var _ datasource.DataSource = &UserPublicKeyDataSource{}
+
+// optionPattern matches the option values without control characters, a
+// newline would end the authorized_keys line before the key.
+var optionPattern = regexp.MustCompile(`^[^\x00-\x1f\x7f]*$`)
+
+func NewUserPublicKeyDataSource() datasource.DataSource {
+	return &UserPublicKeyDataSource{}
+}
+
+type UserPublicKeyDataSource struct {
+	client *aws.AWSCloud9Client
+}
+
+type UserPublicKeyDataSourceModel struct {
+	ID                types.String `tfsdk:"id"`
+	From              types.List   `tfsdk:"from"`
+	Command           types.String `tfsdk:"command"`
+	PublicKey         types.String `tfsdk:"public_key"`
+	KeyType           types.String `tfsdk:"key_type"`
+	FingerprintMD5    types.String `tfsdk:"fingerprint_md5"`
+	FingerprintSHA256 types.String `tfsdk:"fingerprint_sha256"`
+	AuthorizedKey     types.String `tfsdk:"authorized_key"`
+}
+
+func (ds *UserPublicKeyDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_public_key"
+}
+
+func (ds *UserPublicKeyDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The public key cloud9 uses to connect to the SSH environments of the caller, to be added to the authorized keys of the hosts",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "The SHA256 fingerprint of the key",
+				Computed:            true,
+			},
+			"from": schema.ListAttribute{
+				MarkdownDescription: "The `from=` option of `authorized_key`, the host patterns allowed to connect with the key",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+					listvalidator.ValueStringsAre(
+						stringvalidator.LengthAtLeast(1),
+						stringvalidator.RegexMatches(optionPattern, "must not contain control characters"),
+					),
+				},
+			},
+			"command": schema.StringAttribute{
+				MarkdownDescription: "The `command=` option of `authorized_key`, the command forced when connecting with the key",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(optionPattern, "must not contain control characters"),
+				},
+			},
+			"public_key": schema.StringAttribute{
+				MarkdownDescription: "The public key, in the authorized_keys format",
+				Computed:            true,
+			},
+			"key_type": schema.StringAttribute{
+				MarkdownDescription: "The type of the key, e.g. `ssh-rsa`",
+				Computed:            true,
+			},
+			"fingerprint_md5": schema.StringAttribute{
+				MarkdownDescription: "The MD5 fingerprint of the key, formatted like `aa:bb:...`",
+				Computed:            true,
+			},
+			"fingerprint_sha256": schema.StringAttribute{
+				MarkdownDescription: "The SHA256 fingerprint of the key, formatted like `SHA256:...`",
+				Computed:            true,
+			},
+			"authorized_key": schema.StringAttribute{
+				MarkdownDescription: "A line to append to an authorized_keys file, with the `from` and `command` options",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (ds *UserPublicKeyDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*aws.AWSCloud9Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *aws.AWSCloud9Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	ds.client = client
+}
+
+// quoteOption quotes the value of an authorized_keys option, the
+// backslashes and the double quotes are escaped with a backslash. Values
+// with control characters can not be quoted and are rejected.
+func quoteOption(name string, value string) (string, error) {
+	if !optionPattern.MatchString(value) {
+		return "", fmt.Errorf("the %s option must not contain control characters", name)
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`, nil
+}
+
+// authorizedKeyLine prefixes a public key with the from= and command=
+// options when they are set.
+func authorizedKeyLine(publicKey string, from []string, command string) (string, error) {
+	options := make([]string, 0, 2)
+	if len(from) > 0 {
+		option, err := quoteOption("from", strings.Join(from, ","))
+		if err != nil {
+			return "", err
+		}
+		options = append(options, "from="+option)
+	}
+	if len(command) > 0 {
+		option, err := quoteOption("command", command)
+		if err != nil {
+			return "", err
+		}
+		options = append(options, "command="+option)
+	}
+
+	if len(options) == 0 {
+		return publicKey, nil
+	}
+	return strings.Join(options, ",") + " " + publicKey, nil
+}
+
+func (ds *UserPublicKeyDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data UserPublicKeyDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var from []string
+	resp.Diagnostics.Append(data.From.ElementsAs(ctx, &from, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	result, err := ds.client.GetUserPublicKey(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError("Client error", fmt.Sprintf("Could not get the public key of the user: %s", err.Error()))
+		return
+	}
+
+	publicKey := strings.TrimSpace(result.PublicKey)
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(publicKey))
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid public key", fmt.Sprintf("Could not parse the public key returned by cloud9: %s", err.Error()))
+		return
+	}
+
+	authorizedKey, err := authorizedKeyLine(publicKey, from, data.Command.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid authorized key options", err.Error())
+		return
+	}
+
+	data.ID = types.StringValue(ssh.FingerprintSHA256(key))
+	data.PublicKey = types.StringValue(publicKey)
+	data.KeyType = types.StringValue(key.Type())
+	data.FingerprintMD5 = types.StringValue(ssh.FingerprintLegacyMD5(key))
+	data.FingerprintSHA256 = types.StringValue(ssh.FingerprintSHA256(key))
+	data.AuthorizedKey = types.StringValue(authorizedKey)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func TestAuthorizedKeyLine(t *testing.T) {
+	key := "ssh-rsa AAAA cloud9"
+
+	lines := []struct {
+		from     []string
+		command  string
+		expected string
+	}{
+		{nil, "", key},
+		{[]string{"10.0.0.0/8", "*.example.com"}, "", `from="10.0.0.0/8,*.example.com" ssh-rsa AAAA cloud9`},
+		{nil, `echo "hello"`, `command="echo \"hello\"" ssh-rsa AAAA cloud9`},
+		{nil, `echo \"hello\" C:\`, `command="echo \\\"hello\\\" C:\\" ssh-rsa AAAA cloud9`},
+		{[]string{"10.0.0.1"}, "/usr/bin/true", `from="10.0.0.1",command="/usr/bin/true" ssh-rsa AAAA cloud9`},
+	}
+
+	for _, line := range lines {
+		if res, err := authorizedKeyLine(key, line.from, line.command); err != nil || res != line.expected {
+			t.Errorf("expected %s, got %s: %v", line.expected, res, err)
+		}
+	}
+
+	// a newline would start a second, unrestricted, line
+	invalid := []struct {
+		from    []string
+		command string
+	}{
+		{nil, "ls\nssh-ed25519 AAAA attacker"},
+		{nil, "ls\r"},
+		{[]string{"10.0.0.1", "*\nssh-ed25519 AAAA attacker"}, ""},
+		{[]string{"10.0.0.1\x00"}, "ls"},
+	}
+	for _, line := range invalid {
+		if res, err := authorizedKeyLine(key, line.from, line.command); err == nil {
+			t.Errorf("expected %q and %q to be rejected, got %s", line.from, line.command, res)
+		}
+	}
+}
+
+func TestAccUserPublicKeyDataSource(t *testing.T) {
+	server := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + `
+data "awscloud9_user_public_key" "plain" {}
+
+data "awscloud9_user_public_key" "restricted" {
+  from    = ["10.0.0.0/8"]
+  command = "/usr/bin/true"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.awscloud9_user_public_key.plain", "public_key", fakecloud9.DEFAULT_PUBLIC_KEY),
+					resource.TestCheckResourceAttr("data.awscloud9_user_public_key.plain", "key_type", "ssh-rsa"),
+					resource.TestCheckResourceAttr("data.awscloud9_user_public_key.plain", "fingerprint_md5", "63:f7:ee:a8:6d:5e:6f:f5:37:cb:87:f1:5b:db:a0:2d"),
+					resource.TestCheckResourceAttr("data.awscloud9_user_public_key.plain", "fingerprint_sha256", "SHA256:emaEttS567vyNBIkfux7InGWSEKAmwTbPB3XjYZVsKQ"),
+					resource.TestCheckResourceAttr("data.awscloud9_user_public_key.plain", "authorized_key", fakecloud9.DEFAULT_PUBLIC_KEY),
+					resource.TestCheckResourceAttr("data.awscloud9_user_public_key.restricted", "authorized_key",
+						`from="10.0.0.0/8",command="/usr/bin/true" `+fakecloud9.DEFAULT_PUBLIC_KEY),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
+)
+
+// USER_SETTINGS_ID is the id of the user settings, there is a single
+// document per caller.
+const USER_SETTINGS_ID = "user"
+
+var (
+	_ resource.Resource                = &UserSettingsResource{}
+	_ resource.ResourceWithConfigure   = &UserSettingsResource{}
+	_ resource.ResourceWithImportState = &UserSettingsResource{}
+)
+
+type UserSettingsResource struct {
+	client *aws.AWSCloud9Client
+}
+
+type userSettingsModel struct {
+	ID       types.String `tfsdk:"id"`
+	Settings types.String `tfsdk:"settings"`
+}
+
+func NewUserSettingsResource() resource.Resource {
+	return &UserSettingsResource{}
+}
+
+func (rs *UserSettingsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_settings"
+}
+
+func (rs *UserSettingsResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "The IDE settings of the caller, such as the editor preferences, shared by all of its environments. Destroying the resource resets the settings to an empty document",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Always `user`",
+				PlanModifiers:       []planmodifier.String{stringplanmodifier.UseStateForUnknown()},
+			},
+			"settings": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The settings, as a JSON object. Formatting and key order changes, in the configuration or made outside of terraform, are ignored",
+				Validators: []validator.String{
+					validateSettings(),
+				},
+				PlanModifiers: []planmodifier.String{
+					useEquivalentSettings(),
+				},
+			},
+		},
+	}
+}
+
+func (rs *UserSettingsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*aws.AWSCloud9Client)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure type",
+			fmt.Sprintf("Expected *aws.AWSCloud9Client, got %T. Please report this issue to the provider developers.", req.ProviderData))
+		return
+	}
+
+	rs.client = client
+}
+
+func (rs *UserSettingsResource) settings() settingsTarget {
+	return settingsTarget{
+		name:   "the user",
+		get:    rs.client.GetUserSettings,
+		update: rs.client.UpdateUserSettings,
+	}
+}
+
+func (rs *UserSettingsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan userSettingsModel
+
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(rs.settings().apply(ctx, plan.Settings)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(USER_SETTINGS_ID)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (rs *UserSettingsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state userSettingsModel
+
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	settings, _, diags := rs.settings().read(ctx, state.Settings)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.ID = types.StringValue(USER_SETTINGS_ID)
+	state.Settings = settings
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (rs *UserSettingsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state userSettingsModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(rs.settings().change(ctx, state.Settings, plan.Settings)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(USER_SETTINGS_ID)
+
+	diags := resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (rs *UserSettingsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	resp.Diagnostics.Append(rs.settings().reset(ctx)...)
+}
+
+func (rs *UserSettingsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	if req.ID != USER_SETTINGS_ID {
+		resp.Diagnostics.AddError("Format error", fmt.Sprintf("Expected the id to be %q, the settings of the caller are imported", USER_SETTINGS_ID))
+		return
+	}
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+)
+
+func testAccUserSettingsConfig(tabSize int) string {
+	return fmt.Sprintf(`
+resource "awscloud9_user_settings" "test" {
+  settings = jsonencode({
+    ace = {
+      tabSize = %d
+      theme   = "ace/theme/monokai"
+    }
+  })
+}
+`, tabSize)
+}
+
+func testAccCheckUserSettings(server *fakecloud9.Server, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if settings := server.UserSettings(); settings != expected {
+			return fmt.Errorf("expected the user settings to be %s, got %s", expected, settings)
+		}
+		return nil
+	}
+}
+
+func TestAccUserSettingsResource(t *testing.T) {
+	server := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		CheckDestroy:             testAccCheckUserSettings(server, "{}"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccProviderConfig(server) + testAccUserSettingsConfig(2),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("awscloud9_user_settings.test", "id", "user"),
+					testAccCheckUserSettings(server, `{"ace":{"tabSize":2,"theme":"ace/theme/monokai"}}`),
+				),
+			},
+			{
+				// the settings are reformatted outside of terraform
+				PreConfig: func() {
+					server.SetUserSettings(`{"ace": {"theme": "ace/theme/monokai", "tabSize": 2}}`)
+				},
+				Config:   testAccProviderConfig(server) + testAccUserSettingsConfig(2),
+				PlanOnly: true,
+			},
+			{
+				PreConfig: func() {
+					server.SetUserSettings(`{"ace": {"theme": "ace/theme/monokai", "tabSize": 8}}`)
+				},
+				Config:             testAccProviderConfig(server) + testAccUserSettingsConfig(2),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccUserSettingsConfig(4),
+				Check:  testAccCheckUserSettings(server, `{"ace":{"tabSize":4,"theme":"ace/theme/monokai"}}`),
+			},
+			{
+				// only the formatting of the configured document changes
+				Config: testAccProviderConfig(server) + `
+resource "awscloud9_user_settings" "test" {
+  settings = <<-EOT
+    {
+      "ace": {"theme": "ace/theme/monokai", "tabSize": 4}
+    }
+  EOT
+}
+`,
+				PlanOnly: true,
+			},
+			{
+				ResourceName:      "awscloud9_user_settings.test",
+				ImportState:       true,
+				ImportStateId:     "user",
+				ImportStateVerify: true,
+			},
+			{
+				// the settings are emptied outside of terraform
+				PreConfig: func() {
+					server.SetUserSettings("")
+				},
+				Config:             testAccProviderConfig(server) + testAccUserSettingsConfig(4),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				ResourceName:  "awscloud9_user_settings.test",
+				ImportState:   true,
+				ImportStateId: "user",
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 || states[0].Attributes["settings"] != "{}" {
+						return fmt.Errorf("expected the empty settings to be imported as {}, got %v", states)
+					}
+					return nil
+				},
+			},
+			{
+				Config: testAccProviderConfig(server) + testAccUserSettingsConfig(4),
+				Check:  testAccCheckUserSettings(server, `{"ace":{"tabSize":4,"theme":"ace/theme/monokai"}}`),
+			},
+		},
+	})
+}
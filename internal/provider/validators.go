@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/aws"
+)
+
+// userArnPattern matches the ARNs accepted by CreateEnvironmentMembership:
+// IAM users, account roots, assumed roles and federated users, in any
+// partition.
+var userArnPattern = regexp.MustCompile(`^arn:aws(-[a-z]+)*:(iam::\d{12}:root|iam::\d{12}:user/([\w+=,.@-]+/)*[\w+=,.@-]{1,64}|sts::\d{12}:assumed-role/[\w+=,.@-]{1,64}/[\w+=,.@-]{2,64}|sts::\d{12}:federated-user/[\w+=,.@-]{2,32})$`)
+
+var _ validator.String = userArnValidator{}
+
+type userArnValidator struct{}
+
+func (v userArnValidator) Description(ctx context.Context) string {
+	return "value must be the ARN of an IAM user, an account root, an assumed role or a federated user"
+}
+
+func (v userArnValidator) MarkdownDescription(ctx context.Context) string {
+	return "value must be the ARN of an IAM user (`arn:aws:iam::123456789012:user/name`), an account root (`arn:aws:iam::123456789012:root`), an assumed role (`arn:aws:sts::123456789012:assumed-role/role/session`) or a federated user (`arn:aws:sts::123456789012:federated-user/name`)"
+}
+
+func (v userArnValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if !userArnPattern.MatchString(value) {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid user ARN",
+			fmt.Sprintf("%q is not a valid user ARN, %s.", value, v.MarkdownDescription(ctx)))
+	}
+}
+
+// validateUserArn checks that a string is the ARN of a user which can be
+// given a membership.
+func validateUserArn() validator.String {
+	return userArnValidator{}
+}
+
+// validateMemberPermissions only allows the permissions which can be given
+// to a member, owner can not be assigned.
+func validateMemberPermissions() validator.String {
+	return stringvalidator.OneOf(aws.READ_WRITE, aws.READONLY)
+}
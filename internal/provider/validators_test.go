@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestUserArnValidator(t *testing.T) {
+	arns := map[string]bool{
+		"arn:aws:iam::123456789012:user/alice":                           true,
+		"arn:aws:iam::123456789012:user/division/team/alice":             true,
+		"arn:aws-cn:iam::123456789012:user/alice":                        true,
+		"arn:aws-us-gov:iam::123456789012:user/alice":                    true,
+		"arn:aws-iso-b:iam::123456789012:user/alice":                     true,
+		"arn:aws-iso-e:iam::123456789012:user/alice":                     true,
+		"arn:aws-iso-f:sts::123456789012:assumed-role/Admin/alice":       true,
+		"arn:aws-:iam::123456789012:user/alice":                          false,
+		"arn:gcp:iam::123456789012:user/alice":                           false,
+		"arn:aws:sts::123456789012:assumed-role/Admin/alice@example.com": true,
+		"arn:aws:sts::123456789012:federated-user/alice":                 true,
+		"arn:aws:iam::123456789012:role/Admin":                           false,
+		"arn:aws:iam::123456789012:root":                                 true,
+		"arn:aws:sts::123456789012:user/alice":                           false,
+		"arn:aws:iam::12345:user/alice":                                  false,
+		"arn:aws:iam::123456789012:root/alice":                           false,
+		"arn:aws:sts::123456789012:assumed-role/Admin":                   false,
+		"alice": false,
+		"":      false,
+	}
+
+	for arn, valid := range arns {
+		resp := &validator.StringResponse{}
+		validateUserArn().ValidateString(context.Background(), validator.StringRequest{
+			Path:        path.Root("user_arn"),
+			ConfigValue: types.StringValue(arn),
+		}, resp)
+
+		if resp.Diagnostics.HasError() == valid {
+			t.Errorf("%q: expected valid=%t, got %v", arn, valid, resp.Diagnostics)
+		}
+	}
+
+	resp := &validator.StringResponse{}
+	validateUserArn().ValidateString(context.Background(), validator.StringRequest{
+		Path:        path.Root("user_arn"),
+		ConfigValue: types.StringUnknown(),
+	}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Errorf("unknown values must not be validated")
+	}
+}
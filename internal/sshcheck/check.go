@@ -0,0 +1,258 @@
+// Package sshcheck checks that an SSH environment host can be reached and
+// that the cloud9 public key is authorized on it, without ever holding the
+// matching private key: the key is only offered, which the server answers
+// before any signature is required.
+package sshcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+const DEFAULT_TIMEOUT = 10 * time.Second
+
+const (
+	STAGE_CONNECT         = "connect"
+	STAGE_HANDSHAKE       = "handshake"
+	STAGE_AUTHORIZED_KEYS = "authorized_keys"
+	STAGE_TUNNEL          = "tunnel"
+)
+
+// Endpoint is an SSH server and the user to log in with.
+type Endpoint struct {
+	User string
+	Host string
+	Port int
+}
+
+func (e Endpoint) Address() string {
+	return net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+}
+
+func (e Endpoint) String() string {
+	return e.User + "@" + e.Address()
+}
+
+type Config struct {
+	Target Endpoint
+	// Bastion is the host the target is reached through, if any.
+	Bastion *Endpoint
+	// PublicKey is the key which must be authorized on the target and the
+	// bastion, in the authorized_keys format.
+	PublicKey string
+	// BastionAuth authenticates the tunnel through the bastion, the keys of
+	// the ssh agent from SSH_AUTH_SOCK are used when empty.
+	BastionAuth []ssh.AuthMethod
+	// Timeout bounds each connection, defaults to DEFAULT_TIMEOUT.
+	Timeout time.Duration
+}
+
+// Error is a failed check, Stage tells which step failed.
+type Error struct {
+	Endpoint Endpoint
+	Stage    string
+	Err      error
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Summary returns a short description of the failure.
+func (e *Error) Summary() string {
+	switch e.Stage {
+	case STAGE_CONNECT:
+		return "Host unreachable"
+	case STAGE_HANDSHAKE:
+		return "SSH handshake failed"
+	case STAGE_AUTHORIZED_KEYS:
+		return "Public key not authorized"
+	default:
+		return "Bastion tunnel failed"
+	}
+}
+
+func (e *Error) Error() string {
+	switch e.Stage {
+	case STAGE_CONNECT:
+		return fmt.Sprintf("could not connect to %s: %s, check the hostname, the port and the firewall rules", e.Endpoint.Address(), e.Err)
+	case STAGE_HANDSHAKE:
+		return fmt.Sprintf("ssh handshake with %s failed: %s, check that an ssh server listens on port %d", e.Endpoint.Address(), e.Err, e.Endpoint.Port)
+	case STAGE_AUTHORIZED_KEYS:
+		return fmt.Sprintf("the cloud9 public key is not authorized for %s: %s, add the key returned by GetUserPublicKey to ~%s/.ssh/authorized_keys", e.Endpoint, e.Err, e.Endpoint.User)
+	default:
+		return fmt.Sprintf("could not open a tunnel through the bastion %s: %s, the tunnel is authenticated with the keys of the local ssh agent", e.Endpoint, e.Err)
+	}
+}
+
+// Check connects to the target, through the bastion when there is one, and
+// checks that the public key is authorized on both hosts.
+//
+// Host keys are not verified: the public key is only offered, so neither
+// credentials nor data are sent to the target.
+func Check(ctx context.Context, config *Config) error {
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(config.PublicKey))
+	if err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DEFAULT_TIMEOUT
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+
+	if config.Bastion == nil {
+		conn, err := dialer.DialContext(ctx, "tcp", config.Target.Address())
+		if err != nil {
+			return &Error{config.Target, STAGE_CONNECT, err}
+		}
+		return probe(ctx, conn, config.Target, key, timeout)
+	}
+
+	bastion := *config.Bastion
+	conn, err := dialer.DialContext(ctx, "tcp", bastion.Address())
+	if err != nil {
+		return &Error{bastion, STAGE_CONNECT, err}
+	}
+	if err := probe(ctx, conn, bastion, key, timeout); err != nil {
+		return err
+	}
+
+	auth := config.BastionAuth
+	if len(auth) == 0 {
+		agentAuth, closeAgent, err := agentAuthMethod()
+		if err != nil {
+			return &Error{bastion, STAGE_TUNNEL, err}
+		}
+		defer closeAgent()
+		auth = []ssh.AuthMethod{agentAuth}
+	}
+
+	conn, err = dialer.DialContext(ctx, "tcp", bastion.Address())
+	if err != nil {
+		return &Error{bastion, STAGE_CONNECT, err}
+	}
+	stop := closeOnDone(ctx, conn)
+	defer stop()
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, bastion.Address(), &ssh.ClientConfig{
+		User:            bastion.User,
+		Auth:            auth,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         timeout,
+	})
+	if err != nil {
+		conn.Close()
+		return &Error{bastion, STAGE_TUNNEL, err}
+	}
+	client := ssh.NewClient(clientConn, channels, requests)
+	defer client.Close()
+
+	targetConn, err := client.Dial("tcp", config.Target.Address())
+	if err != nil {
+		return &Error{config.Target, STAGE_CONNECT, err}
+	}
+	return probe(ctx, targetConn, config.Target, key, timeout)
+}
+
+func agentAuthMethod() (ssh.AuthMethod, func(), error) {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if len(socket) == 0 {
+		return nil, nil, errors.New("SSH_AUTH_SOCK is not set")
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not connect to the ssh agent: %w", err)
+	}
+	return ssh.PublicKeysCallback(agent.NewClient(conn).Signers), func() { conn.Close() }, nil
+}
+
+// closeOnDone closes conn when ctx is done, until stop is called.
+func closeOnDone(ctx context.Context, conn io.Closer) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
+
+// probe performs the handshake on conn and offers the key, conn is closed
+// once done.
+func probe(ctx context.Context, conn net.Conn, endpoint Endpoint, key ssh.PublicKey, timeout time.Duration) error {
+	defer conn.Close()
+	stop := closeOnDone(ctx, conn)
+	defer stop()
+
+	// tunneled connections do not support deadlines, they are bounded by
+	// the bastion connection
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	signer := &probeSigner{key: key}
+	authStarted := false
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, endpoint.Address(), &ssh.ClientConfig{
+		User: endpoint.User,
+		Auth: []ssh.AuthMethod{
+			ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+				authStarted = true
+				return []ssh.Signer{signer}, nil
+			}),
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         timeout,
+	})
+	if err == nil {
+		// the server does not require any authentication
+		ssh.NewClient(clientConn, channels, requests).Close()
+		return nil
+	}
+	if signer.accepted {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if !authStarted {
+		return &Error{endpoint, STAGE_HANDSHAKE, err}
+	}
+	return &Error{endpoint, STAGE_AUTHORIZED_KEYS, err}
+}
+
+var errKeyAccepted = errors.New("key accepted")
+
+// probeSigner offers a public key without its private key: the server only
+// asks for a signature once it accepted the key, which aborts the
+// authentication.
+type probeSigner struct {
+	key      ssh.PublicKey
+	accepted bool
+}
+
+var _ ssh.AlgorithmSigner = &probeSigner{}
+
+func (s *probeSigner) PublicKey() ssh.PublicKey {
+	return s.key
+}
+
+func (s *probeSigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	return s.SignWithAlgorithm(rand, data, "")
+}
+
+func (s *probeSigner) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	s.accepted = true
+	return nil, errKeyAccepted
+}
@@ -0,0 +1,158 @@
+package sshcheck
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakecloud9"
+	"github.com/m1dugh/terraform-provider-awscloud9/internal/fakessh"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestServer(t *testing.T) *fakessh.Server {
+	server := fakessh.NewServer()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func endpoint(server *fakessh.Server, user string) Endpoint {
+	return Endpoint{User: user, Host: server.Host, Port: server.Port}
+}
+
+func expectStage(t *testing.T, err error, stage string) {
+	t.Helper()
+
+	var checkErr *Error
+	if !errors.As(err, &checkErr) {
+		t.Fatalf("expected a check error, got %v", err)
+	}
+	if checkErr.Stage != stage {
+		t.Errorf("expected stage %s, got %s: %s", stage, checkErr.Stage, checkErr)
+	}
+}
+
+func TestCheckDirect(t *testing.T) {
+	server := newTestServer(t)
+	server.Authorize("ubuntu", fakecloud9.DEFAULT_PUBLIC_KEY)
+
+	err := Check(context.Background(), &Config{
+		Target:    endpoint(server, "ubuntu"),
+		PublicKey: fakecloud9.DEFAULT_PUBLIC_KEY,
+	})
+	if err != nil {
+		t.Fatalf("expected the check to succeed, got %s", err)
+	}
+
+	err = Check(context.Background(), &Config{
+		Target:    endpoint(server, "root"),
+		PublicKey: fakecloud9.DEFAULT_PUBLIC_KEY,
+	})
+	expectStage(t, err, STAGE_AUTHORIZED_KEYS)
+}
+
+func TestCheckUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().(*net.TCPAddr)
+	listener.Close()
+
+	err = Check(context.Background(), &Config{
+		Target:    Endpoint{User: "ubuntu", Host: "127.0.0.1", Port: addr.Port},
+		PublicKey: fakecloud9.DEFAULT_PUBLIC_KEY,
+	})
+	expectStage(t, err, STAGE_CONNECT)
+}
+
+func TestCheckNotSSH(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+			conn.Close()
+		}
+	}()
+
+	err = Check(context.Background(), &Config{
+		Target:    Endpoint{User: "ubuntu", Host: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port},
+		PublicKey: fakecloud9.DEFAULT_PUBLIC_KEY,
+		Timeout:   time.Second,
+	})
+	expectStage(t, err, STAGE_HANDSHAKE)
+}
+
+func TestCheckBastion(t *testing.T) {
+	bastion := newTestServer(t)
+	target := newTestServer(t)
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	operator, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bastion.Authorize("jump", fakecloud9.DEFAULT_PUBLIC_KEY)
+	bastion.AuthorizeKey("jump", operator.PublicKey())
+
+	bastionEndpoint := endpoint(bastion, "jump")
+	config := &Config{
+		Target:      endpoint(target, "ubuntu"),
+		Bastion:     &bastionEndpoint,
+		PublicKey:   fakecloud9.DEFAULT_PUBLIC_KEY,
+		BastionAuth: []ssh.AuthMethod{ssh.PublicKeys(operator)},
+	}
+
+	// the key is missing on the target
+	err = Check(context.Background(), config)
+	expectStage(t, err, STAGE_AUTHORIZED_KEYS)
+	if checkErr := err.(*Error); checkErr.Endpoint != config.Target {
+		t.Errorf("expected the target to be reported, got %s", checkErr.Endpoint)
+	}
+
+	target.Authorize("ubuntu", fakecloud9.DEFAULT_PUBLIC_KEY)
+	if err := Check(context.Background(), config); err != nil {
+		t.Fatalf("expected the check to succeed, got %s", err)
+	}
+	if bastion.Forwards() != 2 {
+		t.Errorf("expected the target to be reached through the bastion, got %d forwards", bastion.Forwards())
+	}
+
+	// the operator can not open a tunnel
+	config.BastionAuth = []ssh.AuthMethod{ssh.Password("secret")}
+	err = Check(context.Background(), config)
+	expectStage(t, err, STAGE_TUNNEL)
+
+	// the key is missing on the bastion
+	bastionEndpoint.User = "admin"
+	err = Check(context.Background(), config)
+	expectStage(t, err, STAGE_AUTHORIZED_KEYS)
+}
+
+func TestCheckInvalidKey(t *testing.T) {
+	server := newTestServer(t)
+
+	err := Check(context.Background(), &Config{
+		Target:    endpoint(server, "ubuntu"),
+		PublicKey: "not a key",
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+}